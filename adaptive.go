@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// adaptive ttl of a key
+type adaptState struct {
+	sum uint64 // of the last loaded value
+	ttl int64
+	at  int64 // when it's loaded last time
+}
+
+type adaptive struct {
+	min, max int64
+	sum      func(v interface{}) uint64
+	states   []side[adaptState]
+}
+
+// AdaptiveTTL - adapt the ttl of each key loaded by `GetOrLoadWith` (and `LFURefresh`) to how often its value changes:
+// a reload finding the same value doubles the ttl of the key (up to `max`), a changed one halves it (down to `min`),
+// so stable keys cost fewer backend calls and volatile ones stay fresh, starting from `expire` of the cache,
+// values are compared by `sum`, nil to hash strings and []byte as they are and others in form of "%#v",
+// `GetOrLoadWithTTL` (and `GetOrLoadWithRemoteTTL`) keeps the ttl given by the caller (or the remote tier)
+func (c *Cache) AdaptiveTTL(min, max time.Duration, sum func(v interface{}) uint64) *Cache {
+	if min <= 0 || max < min {
+		c.adaptive = nil
+		return c
+	}
+	if sum == nil {
+		sum = valueSum
+	}
+	c.adaptive = &adaptive{int64(min), int64(max), sum, make([]side[adaptState], len(c.insts))}
+	return c
+}
+
+func valueSum(v interface{}) uint64 {
+	h := fnv.New64a()
+	switch v := v.(type) {
+	case string:
+		h.Write([]byte(v))
+	case []byte:
+		h.Write(v)
+	default:
+		fmt.Fprintf(h, "%#v", v)
+	}
+	return h.Sum64()
+}
+
+// expiration of the loaded value `v` of key, with its ttl adapted
+func (c *Cache) adapt(key string, v interface{}) int64 {
+	a, sum := c.adaptive, c.adaptive.sum(v)
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	now := c.clock.Now()
+	st, ok := a.states[idx].m[key]
+	switch {
+	case !ok:
+		st.ttl = int64(c.expire[0])
+	case st.sum == sum:
+		if st.ttl > a.max/2 {
+			st.ttl = a.max // not to overflow
+		} else {
+			st.ttl *= 2
+		}
+	default:
+		st.ttl /= 2
+	}
+	if st.ttl < a.min {
+		st.ttl = a.min
+	} else if st.ttl > a.max {
+		st.ttl = a.max
+	}
+	st.sum, st.at = sum, now
+	a.states[idx].set(key, st, func(_ string, s adaptState) bool {
+		return (now-s.at)/2 > a.max // its ttl would have grown to max
+	})
+	return after(now, st.ttl)
+}
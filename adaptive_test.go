@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_AdaptiveTTL(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 8, 4*time.Second).Clock(clk).AdaptiveTTL(time.Second, 16*time.Second, nil)
+	val, loads := "a", 0
+	loader := func(string) (interface{}, error) {
+		loads++
+		return val, nil
+	}
+	get := func() { lc.GetOrLoadWith("1", loader) }
+
+	get() // 4s to start with
+	clk.Add(3 * time.Second)
+	get()
+	if loads != 1 {
+		t.Error("case 1 failed")
+	}
+	clk.Add(2 * time.Second)
+	get() // unchanged, 8s
+	clk.Add(7 * time.Second)
+	get()
+	if loads != 2 {
+		t.Error("case 2 failed")
+	}
+	clk.Add(2 * time.Second)
+	get() // unchanged, 16s
+	clk.Add(20 * time.Second)
+	get() // unchanged, still 16s
+	clk.Add(15 * time.Second)
+	get()
+	if loads != 4 {
+		t.Error("case 3 failed")
+	}
+
+	val = "b"
+	clk.Add(2 * time.Second)
+	get() // changed, 8s
+	clk.Add(9 * time.Second)
+	get() // unchanged, 16s
+	if loads != 6 {
+		t.Error("case 4 failed")
+	}
+	val = "c"
+	clk.Add(17 * time.Second)
+	get() // 8s
+	val = "d"
+	clk.Add(9 * time.Second)
+	get() // 4s
+	val = "e"
+	clk.Add(5 * time.Second)
+	get() // 2s
+	val = "f"
+	clk.Add(3 * time.Second)
+	get() // 1s
+	val = "g"
+	clk.Add(2 * time.Second)
+	get() // still 1s
+	clk.Add(1500 * time.Millisecond)
+	get()
+	if loads != 12 {
+		t.Error("case 5 failed")
+	}
+
+	// ttl given by the caller is kept
+	lc.GetOrLoadWithTTL("2", time.Hour, loader)
+	clk.Add(time.Minute)
+	if _, ok := lc.Get("2"); !ok {
+		t.Error("case 6 failed")
+	}
+
+	if valueSum([]byte("x")) != valueSum("x") || valueSum(1) == valueSum(2) {
+		t.Error("case 7 failed")
+	}
+
+	// the ttl saturates at max instead of overflowing
+	lc = NewLRUCache(1, 8, 4*time.Second).Clock(clk).AdaptiveTTL(time.Second, math.MaxInt64, nil)
+	for i := 0; i < 70; i++ {
+		lc.Del("3") // the state of the key is kept
+		lc.GetOrLoadWith("3", loader)
+	}
+	clk.Add(time.Hour)
+	if _, ok := lc.Get("3"); !ok {
+		t.Error("case 8 failed")
+	}
+}
@@ -0,0 +1,32 @@
+package cache
+
+// LFUMaxSize - don't promote items larger than `maxSize` to upper-level-cache, they stay in level-0 as normal lru items
+// so one huge blob earning promotion won't evict lots of hot small items of level-1
+// `sizeOf` measures the value as stored (i.e. encoded by `Pipeline` if any), nil means length of string or []byte (others are 0)
+func (c *Cache) LFUMaxSize(maxSize int, sizeOf func(v interface{}) int) *Cache {
+	if sizeOf == nil {
+		sizeOf = sizeOfValue
+	}
+	c.admit = func(w *wrapper) bool { return sizeOf(w.v) <= maxSize }
+	return c
+}
+
+// length of string or []byte, 0 for others
+func sizeOfValue(v interface{}) int {
+	switch v := v.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	}
+	return 0
+}
+
+// whether the item can be promoted to level-1 of bucket `idx`
+func (c *Cache) promotable(idx int, w *wrapper) bool {
+	if c.admit != nil && !c.admit(w) {
+		return false
+	}
+	budget := c.insts[idx][1].budget
+	return budget <= 0 || w.cost <= budget
+}
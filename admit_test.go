@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_LFUMaxSize(t *testing.T) {
+	lc := NewLRUCache(1, 4, time.Second).LFU(4).LFUMaxSize(4, nil)
+	lc.Put("1", "1234")
+	lc.Put("2", "12345")
+	lc.Put("3", []byte("12345"))
+	lc.Put("4", 12345)
+	for _, k := range []string{"1", "2", "3", "4"} {
+		if _, ok := lc.Get(k); !ok {
+			t.Error("case 1 failed", k)
+		}
+	}
+	if lc.insts[0][0].length() != 2 || lc.insts[0][1].length() != 2 {
+		t.Error("case 2 failed")
+	}
+	if _, ok := lc.insts[0][0].hmap["2"]; !ok {
+		t.Error("case 3 failed")
+	}
+	if _, ok := lc.Get("3"); !ok { // hit again in level-0
+		t.Error("case 4 failed")
+	}
+
+	clk := &fakeClock{}
+	lc = NewLRUCache(1, 3, time.Hour).LFU(3).Decay(time.Minute, 2).Clock(clk).
+		LFUMaxSize(1, func(v interface{}) int { return v.(int) })
+	lc.Put("1", 1)
+	lc.Put("2", 2)
+	lc.Get("1")
+	lc.Get("2")
+	if lc.insts[0][0].length() != 1 || lc.insts[0][1].length() != 1 {
+		t.Error("case 5 failed")
+	}
+}
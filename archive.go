@@ -0,0 +1,72 @@
+package cache
+
+// max count of items waiting for the archiver by default
+const archiveQueue = 1024
+
+type archived struct {
+	key string
+	val interface{}
+}
+
+type archiver struct {
+	ch    chan archived
+	block bool
+	done  chan struct{}
+}
+
+// Archive - start a background goroutine that calls `fn` with items evicted by capacity (not expired, deleted or replaced ones),
+// e.g. to persist costly computed results to object storage instead of losing them, `val` is as stored
+// at most `queue` (1024 if <= 0) items wait for `fn`, then further ones are dropped and counted in `ArchiveDrops` of stats,
+// or if `block`, evictions wait for room with the lock of the bucket held, so writers of the bucket slow down to the pace of `fn`
+// `Close` stops it after the waiting items are archived, it's a no-op if the archiver is already running
+func (c *Cache) Archive(fn func(key string, val interface{}), queue int, block bool) *Cache {
+	if c.archiver != nil {
+		return c
+	}
+	if queue <= 0 {
+		queue = archiveQueue
+	}
+	a := &archiver{ch: make(chan archived, queue), block: block, done: make(chan struct{})}
+	c.archiver = a
+	go func() {
+		defer close(a.done)
+		for it := range a.ch {
+			fn(it.key, it.val)
+		}
+	}()
+	return c
+}
+
+// hand the evicted item to the archiver, lock of the bucket must be held
+func (c *Cache) archive(key string, idx int, w *wrapper) {
+	if live, _ := c.peek(key, idx); live != nil {
+		return // still in the other level
+	}
+	it := archived{key, w.v}
+	if c.archiver.block {
+		c.archiver.ch <- it
+	} else {
+		select {
+		case c.archiver.ch <- it:
+		default:
+			c.locks[idx].cnts.archiveDrops++
+			return
+		}
+	}
+	c.locks[idx].cnts.archives++
+}
+
+// stop the archiver after the waiting items are archived,
+// all buckets are locked at once so no eviction is being handed to it (blocked ones are unblocked as it keeps draining)
+func (c *Cache) stopArchiver() {
+	a := c.archiver
+	for idx := range c.insts {
+		c.lock(idx)
+	}
+	c.archiver = nil
+	for idx := range c.insts {
+		c.locks[idx].Unlock()
+	}
+	close(a.ch)
+	<-a.done
+}
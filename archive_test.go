@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Archive(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]interface{}{}
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 2, time.Second).Clock(clk).Archive(func(key string, val interface{}) {
+		mu.Lock()
+		got[key] = val
+		mu.Unlock()
+	}, 0, false)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Put("3", "3") // "1" evicted
+	lc.Del("2")
+	lc.Put("3", "4")
+	clk.Add(2 * time.Second)
+	lc.Put("5", "5")
+	lc.Put("6", "6") // "3" evicted after expired
+	lc.Close()
+	if len(got) != 1 || got["1"] != "1" {
+		t.Error("case 1 failed", got)
+	}
+	if s := lc.ShardStats(0); s.Archived != 1 || s.ArchiveDrops != 0 || s.Evictions != 2 {
+		t.Error("case 2 failed")
+	}
+	lc.Put("7", "7") // not archived after closed
+	if len(got) != 1 {
+		t.Error("case 3 failed")
+	}
+
+	// drop when the queue is full
+	release := make(chan struct{})
+	lc = NewLRUCache(1, 1, 0).Archive(func(key string, val interface{}) { <-release }, 1, false)
+	for _, k := range []string{"1", "2", "3", "4", "5"} {
+		lc.Put(k, k)
+	}
+	close(release)
+	lc.Close()
+	if s := lc.ShardStats(0); s.Archived+s.ArchiveDrops != 4 || s.ArchiveDrops < 2 {
+		t.Error("case 4 failed")
+	}
+
+	// block when the queue is full
+	var n int
+	lc = NewLRUCache(1, 1, 0).Archive(func(key string, val interface{}) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		n++
+		mu.Unlock()
+	}, 1, true)
+	for _, k := range []string{"1", "2", "3", "4", "5"} {
+		lc.Put(k, k)
+	}
+	lc.Close()
+	if s := lc.ShardStats(0); n != 4 || s.Archived != 4 || s.ArchiveDrops != 0 {
+		t.Error("case 5 failed")
+	}
+
+	// not archived while it's still in level-1
+	var keys []string
+	lc = NewLRUCache(1, 1, 0).LFU(1).Archive(func(key string, val interface{}) { keys = append(keys, key) }, 0, false)
+	lc.Put("1", "1")
+	lc.Get("1")
+	lc.Put("2", "2")
+	lc.Close()
+	if len(keys) != 0 {
+		t.Error("case 6 failed", keys)
+	}
+}
+
+func Test_ArchivePrealloc(t *testing.T) {
+	var got []interface{}
+	lc := NewLRUCache(1, 2, 0).Prealloc().Archive(func(key string, val interface{}) { got = append(got, val) }, 0, false)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Put("3", "3")
+	lc.Put("4", "4")
+	lc.Close()
+	if len(got) != 2 || got[0] != "1" || got[1] != "2" {
+		t.Error("case 1 failed", got)
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import "unsafe"
+
+// byte arena of keys of a level of a bucket, keys are appended to the current chunk and never overwritten,
+// so keys referring to a chunk (even handed to callbacks) stay valid, and gc frees it once none of them is left
+type arena struct {
+	chunk []byte
+	size  int // of each chunk
+	used  int // bytes copied since the last compaction
+	live  int // bytes of keys copied by the last compaction
+}
+
+// ArenaKeys - copy keys of new items into byte arenas of `chunkSize` bytes of each bucket (and level),
+// so tens of millions of small keys are a few pointer-free chunks instead of as many strings for gc to mark,
+// along with `Prealloc` no object is left per item but values, call it after `LFU` and the other options,
+// the keys of removed items are garbage in their chunk, live keys are compacted into new chunks
+// once as many bytes as theirs plus a chunk are copied, keys longer than a quarter of a chunk are kept as they are,
+// `chunkSize` of `0` (or negative) disables it
+func (c *Cache) ArenaKeys(chunkSize int) *Cache {
+	for idx := range c.insts {
+		c.locks[idx].Lock()
+		for _, inst := range c.insts[idx] {
+			if inst != nil {
+				inst.arena = nil
+				if chunkSize > 0 {
+					inst.arena = &arena{size: chunkSize}
+				}
+			}
+		}
+		c.locks[idx].Unlock()
+	}
+	return c
+}
+
+// copy s into the arena
+func (a *arena) copy(s string) string {
+	if len(s) == 0 || len(s) > a.size/4 {
+		return s
+	}
+	if len(s) > cap(a.chunk)-len(a.chunk) {
+		a.chunk = make([]byte, 0, a.size)
+	}
+	off := len(a.chunk)
+	a.chunk = append(a.chunk, s...)
+	a.used += len(s)
+	b := a.chunk[off:len(a.chunk):len(a.chunk)]
+	return *(*string)(unsafe.Pointer(&b)) // read only, never overwritten
+}
+
+// copy the new key into the arena, K must be string
+func (c *cache[K, V]) own(k K) K {
+	s := c.arena.copy(*(*string)(unsafe.Pointer(&k)))
+	return *(*K)(unsafe.Pointer(&s))
+}
+
+// copy live keys into a new arena if the garbage may outweigh them
+func (c *cache[K, V]) compact() {
+	if c.arena.used <= c.arena.live+c.arena.size {
+		return
+	}
+	hmap := make(map[K]*node[K, V], len(c.hmap))
+	c.arena = &arena{size: c.arena.size}
+	for e := c.head; e != nil; e = e.n {
+		e.k = c.own(e.k)
+		hmap[e.k] = e
+	}
+	c.hmap, c.arena.live, c.arena.used = hmap, c.arena.used, 0
+}
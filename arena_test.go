@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"unsafe"
+)
+
+// address of the bytes of s
+func dataOf(s string) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&s))
+}
+
+// whether s lies in the current chunk of the arena
+func inArena(a *arena, s string) bool {
+	p, lo := dataOf(s), *(*uintptr)(unsafe.Pointer(&a.chunk))
+	return lo <= p && p+uintptr(len(s)) <= lo+uintptr(len(a.chunk))
+}
+
+func Test_ArenaKeys(t *testing.T) {
+	lc := NewLRUCache(1, 4, 0).LFU(4).ArenaKeys(64)
+	lc.Put("key1", 1)
+	if v, ok := lc.Get("key1"); !ok || v != 1 {
+		t.Error("case 1 failed")
+	}
+	for k := range lc.insts[0][1].hmap { // promoted, and copied again
+		if k != "key1" || !inArena(lc.insts[0][1].arena, k) {
+			t.Error("case 2 failed")
+		}
+	}
+
+	// compacted
+	var evicted []string
+	lc = NewLRUCache(1, 4, 0).ArenaKeys(64).OnEvict(func(key string, _ interface{}, _ EvictReason) {
+		evicted = append(evicted, key)
+	})
+	for i := 0; i < 100; i++ {
+		lc.Put("key"+strconv.Itoa(i), i)
+	}
+	a := lc.insts[0][0].arena
+	if a.live == 0 || a.used > a.live+a.size {
+		t.Error("case 3 failed", a.live, a.used)
+	}
+	for k, e := range lc.insts[0][0].hmap {
+		if !inArena(a, k) || !inArena(a, e.k) {
+			t.Error("case 4 failed", k)
+		}
+	}
+	for i := 96; i < 100; i++ {
+		if v, ok := lc.Get("key" + strconv.Itoa(i)); !ok || v != i {
+			t.Error("case 5 failed", i)
+		}
+	}
+	for i, k := range evicted { // still valid after compactions
+		if k != "key"+strconv.Itoa(i) {
+			t.Error("case 6 failed", k)
+		}
+	}
+
+	// long keys are kept as they are
+	k := "a long key of more than a quarter of a chunk"
+	lc.Put(k, 0)
+	for s := range lc.insts[0][0].hmap {
+		if len(s) == len(k) && dataOf(s) != dataOf(k) {
+			t.Error("case 7 failed")
+		}
+	}
+
+	// kept by `ReplaceAll`
+	lc.ReplaceAll(map[string]interface{}{"key0": 0})
+	if a := lc.insts[0][0].arena; a == nil || !inArena(a, lc.insts[0][0].head.k) {
+		t.Error("case 8 failed")
+	}
+	if lc.ArenaKeys(0).insts[0][0].arena != nil {
+		t.Error("case 9 failed")
+	}
+}
+
+func Test_ArenaKeysNoAlloc(t *testing.T) {
+	lc := NewLRUCache(1, 16, 0).Prealloc().ArenaKeys(4096)
+	keys := make([]string, 64)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	var v interface{} = 1
+	i := 0
+	if n := testing.AllocsPerRun(10000, func() {
+		lc.Put(keys[i%len(keys)], v) // new keys with eviction
+		i++
+	}); n > 0.01 { // but chunks and maps of compactions
+		t.Error("case 1 failed", n)
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import "context"
+
+// goroutines waiting for a key
+type waiter struct {
+	ch chan struct{} // closed when the key is put
+	n  int
+}
+
+// wake up goroutines waiting for key, lock of the bucket must be held
+func (c *Cache) wake(key string, idx int) {
+	if w, ok := c.waiters[idx][key]; ok {
+		close(w.ch)
+		delete(c.waiters[idx], key)
+	}
+}
+
+// Await - get value of key, or block until some other goroutine puts it (or `ctx` ends),
+// for pipelines where the producer and the consumer are decoupled, instead of polling in a loop
+func (c *Cache) Await(ctx context.Context, key string) (interface{}, error) {
+	idx := hashCode(key) & c.mask
+	raced := false
+	for {
+		if v, ok := c.Get(key); ok {
+			return v, nil
+		}
+		c.lock(idx)
+		// put right after the `Get`, retry once, or it's unservable (e.g. `DryRun`) and we wait for the next put
+		if cur, _ := c.peek(key, idx); cur != nil && !raced {
+			c.locks[idx].Unlock()
+			raced = true
+			continue
+		}
+		raced = false
+		if c.waiters[idx] == nil {
+			c.waiters[idx] = make(map[string]*waiter)
+		}
+		w, ok := c.waiters[idx][key]
+		if !ok {
+			w = &waiter{ch: make(chan struct{})}
+			c.waiters[idx][key] = w
+		}
+		w.n++
+		c.locks[idx].Unlock()
+
+		select {
+		case <-w.ch: // the item may be gone again before we get it, so retry
+		case <-ctx.Done():
+			c.lock(idx)
+			if w.n--; w.n == 0 && c.waiters[idx][key] == w {
+				delete(c.waiters[idx], key)
+			}
+			c.locks[idx].Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
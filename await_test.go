@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_Await(t *testing.T) {
+	lc := NewLRUCache(1, 3, time.Second)
+	lc.Put("1", "1")
+	if v, err := lc.Await(context.Background(), "1"); err != nil || v != "1" {
+		t.Error("case 1 failed")
+	}
+
+	done := make(chan interface{})
+	for i := 0; i < 3; i++ {
+		go func() {
+			v, _ := lc.Await(context.Background(), "2")
+			done <- v
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	lc.Put("2", "2")
+	for i := 0; i < 3; i++ {
+		select {
+		case v := <-done:
+			if v != "2" {
+				t.Error("case 2 failed")
+			}
+		case <-time.After(time.Second):
+			t.Error("case 3 failed")
+		}
+	}
+	if len(lc.waiters[0]) != 0 {
+		t.Error("case 4 failed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := lc.Await(ctx, "3"); err != context.DeadlineExceeded {
+		t.Error("case 5 failed")
+	}
+	if len(lc.waiters[0]) != 0 {
+		t.Error("case 6 failed")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		lc.PutIfAbsent("4", "4")
+	}()
+	if v, err := lc.Await(context.Background(), "4"); err != nil || v != "4" {
+		t.Error("case 7 failed")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		lc.ReplaceAll(map[string]interface{}{"5": "5"})
+	}()
+	if v, err := lc.Await(context.Background(), "5"); err != nil || v != "5" {
+		t.Error("case 8 failed")
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Imbalance - the most loaded bucket when buckets are out of balance
+type Imbalance struct {
+	Shard    int
+	Len      int      // count of items in the bucket
+	MeanLen  float64  // mean count of items of all buckets
+	Ops      uint64   // gets of the bucket in the period
+	MeanOps  float64  // mean gets of all buckets in the period
+	HitRatio float64  // hit ratio of the bucket in the period
+	TopKeys  []string // most frequently put keys of the bucket, only with `Churn`
+}
+
+// compare buckets by count of items and gets since `prev` (which is updated),
+// returns the most loaded bucket if its load is more than `threshold` times of the mean
+func (c *Cache) balance(prev [][2]uint64, threshold float64) (im Imbalance, ok bool) {
+	n := len(c.insts)
+	lens, hits, misses := make([]int, n), make([]uint64, n), make([]uint64, n)
+	var sumLen, sumOps float64
+	for idx := range c.insts {
+		c.locks[idx].Lock()
+		for _, inst := range c.insts[idx] {
+			if inst != nil {
+				lens[idx] += inst.length()
+			}
+		}
+		hits[idx], misses[idx] = c.locks[idx].cnts.hits-prev[idx][0], c.locks[idx].cnts.misses-prev[idx][1]
+		prev[idx] = [2]uint64{c.locks[idx].cnts.hits, c.locks[idx].cnts.misses}
+		c.locks[idx].Unlock()
+		sumLen += float64(lens[idx])
+		sumOps += float64(hits[idx] + misses[idx])
+	}
+	meanLen, meanOps := sumLen/float64(n), sumOps/float64(n)
+
+	worst, load := -1, threshold
+	for idx := range lens {
+		if meanLen > 0 && float64(lens[idx])/meanLen > load {
+			worst, load = idx, float64(lens[idx])/meanLen
+		}
+		if meanOps > 0 && float64(hits[idx]+misses[idx])/meanOps > load {
+			worst, load = idx, float64(hits[idx]+misses[idx])/meanOps
+		}
+	}
+	if worst < 0 {
+		return
+	}
+	im = Imbalance{Shard: worst, Len: lens[worst], MeanLen: meanLen, Ops: hits[worst] + misses[worst], MeanOps: meanOps}
+	if im.Ops > 0 {
+		im.HitRatio = float64(hits[worst]) / float64(im.Ops)
+	}
+	if c.churn != nil {
+		for _, s := range c.TopChurningKeys(c.churn.k) {
+			if hashCode(s.Key)&c.mask == worst {
+				im.TopKeys = append(im.TopKeys, s.Key)
+			}
+		}
+	}
+	return im, true
+}
+
+// CheckBalance - check whether the most loaded bucket holds more than `threshold` times of the mean count of
+// items, or serves more than `threshold` times of the mean gets since the cache is created
+// which indicates bad key distribution or hot-key problems
+func (c *Cache) CheckBalance(threshold float64) (Imbalance, bool) {
+	return c.balance(make([][2]uint64, len(c.insts)), threshold)
+}
+
+// WatchBalance - start a watchdog that checks balance of buckets every `interval` like `CheckBalance`
+// (but counts gets in each interval only), and calls `fn` when it's out of balance
+// call the returned function to stop it, more calls are no-op
+func (c *Cache) WatchBalance(interval time.Duration, threshold float64, fn func(Imbalance)) (stop func()) {
+	var once sync.Once
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		prev := make([][2]uint64, len(c.insts))
+		c.balance(prev, threshold) // start counting from now
+		for {
+			select {
+			case <-t.C:
+				if im, ok := c.balance(prev, threshold); ok {
+					fn(im)
+				}
+			case <-done:
+				t.Stop()
+				return
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
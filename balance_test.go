@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_CheckBalance(t *testing.T) {
+	lc := NewLRUCache(4, 100, time.Second)
+	if _, ok := lc.CheckBalance(2); ok {
+		t.Error("case 1 failed")
+	}
+	for i := 0; i < 40; i++ {
+		lc.Put(strconv.Itoa(i), i)
+	}
+	if _, ok := lc.CheckBalance(2); ok {
+		t.Error("case 2 failed")
+	}
+
+	lc.Churn(4)
+	hot := "hot"
+	for i := 0; i < 1000; i++ {
+		lc.Put(hot, i)
+		lc.Get(hot)
+	}
+	im, ok := lc.CheckBalance(2)
+	if !ok || im.Shard != hashCode(hot)&lc.mask || im.Ops != 1000 || im.MeanOps != 250 || im.HitRatio != 1 {
+		t.Error("case 3 failed: ", im)
+	}
+	if len(im.TopKeys) == 0 || im.TopKeys[0] != hot {
+		t.Error("case 4 failed: ", im)
+	}
+
+	// all items in bucket 1
+	lc = NewLRUCache(4, 100, time.Second)
+	for i, n := 0, 0; n < 20; i++ {
+		if k := strconv.Itoa(i); hashCode(k)&lc.mask == 1 {
+			lc.Put(k, i)
+			n++
+		}
+	}
+	if im, ok = lc.CheckBalance(2); !ok || im.Shard != 1 || im.Len != 20 || im.MeanLen != 5 {
+		t.Error("case 5 failed: ", im)
+	}
+}
+
+func Test_WatchBalance(t *testing.T) {
+	lc := NewLRUCache(4, 100, time.Second)
+	lc.Get("before") // not counted
+	ch := make(chan Imbalance, 10)
+	stop := lc.WatchBalance(10*time.Millisecond, 2, func(im Imbalance) { ch <- im })
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 100; i++ {
+		lc.Get("hot")
+	}
+	select {
+	case im := <-ch:
+		if im.Shard != hashCode("hot")&lc.mask || im.Ops != 100 {
+			t.Error("case 1 failed: ", im)
+		}
+	case <-time.After(time.Second):
+		t.Error("case 2 failed")
+	}
+	stop()
+	stop() // no-op
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const benchKeyCnt = 1 << 16 // power of 2 for masking
+
+var benchGoroutines = []int{1, 2, 4, 8, 16, 32, 64, 128}
+
+var benchKeys = func() []string {
+	keys := make([]string, benchKeyCnt)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}()
+
+// split b.N operations to `g` goroutines, each one walks its own range of keys
+func runGoroutines(b *testing.B, g int, f func(i int)) {
+	var wg sync.WaitGroup
+	per := (b.N + g - 1) / g
+	b.ResetTimer()
+	for j := 0; j < g; j++ {
+		wg.Add(1)
+		go func(j int) {
+			for i := j * per; i < (j+1)*per && i < b.N; i++ {
+				f(i)
+			}
+			wg.Done()
+		}(j)
+	}
+	wg.Wait()
+}
+
+func benchmarkCache(b *testing.B, newCache func() *Cache, op func(c *Cache, i int)) {
+	for _, g := range benchGoroutines {
+		b.Run(fmt.Sprintf("goroutines-%d", g), func(b *testing.B) {
+			c := newCache()
+			for _, k := range benchKeys {
+				c.Put(k, k)
+			}
+			runGoroutines(b, g, func(i int) { op(c, i) })
+		})
+	}
+}
+
+func newBenchCache() *Cache {
+	return NewLRUCache(AutoBuckets, benchKeyCnt, time.Hour)
+}
+
+func benchGet(c *Cache, i int) {
+	c.Get(benchKeys[i&(benchKeyCnt-1)])
+}
+
+func benchPut(c *Cache, i int) {
+	c.Put(benchKeys[i&(benchKeyCnt-1)], i)
+}
+
+// 90% read, 10% write
+func benchMixed(c *Cache, i int) {
+	if i%10 == 0 {
+		benchPut(c, i)
+	} else {
+		benchGet(c, i)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	benchmarkCache(b, newBenchCache, benchGet)
+}
+
+func BenchmarkPut(b *testing.B) {
+	benchmarkCache(b, newBenchCache, benchPut)
+}
+
+func BenchmarkMixed(b *testing.B) {
+	benchmarkCache(b, newBenchCache, benchMixed)
+}
+
+func BenchmarkMixedLFU(b *testing.B) {
+	benchmarkCache(b, func() *Cache { return newBenchCache().LFU(benchKeyCnt) }, benchMixed)
+}
+
+func BenchmarkMixedPadLocks(b *testing.B) {
+	benchmarkCache(b, func() *Cache { return newBenchCache().PadLocks() }, benchMixed)
+}
+
+func BenchmarkGetNoTTL(b *testing.B) {
+	benchmarkCache(b, func() *Cache { return NewLRUCache(AutoBuckets, benchKeyCnt, 0) }, benchGet)
+}
+
+func BenchmarkMixedPrealloc(b *testing.B) {
+	benchmarkCache(b, func() *Cache { return newBenchCache().Prealloc() }, benchMixed)
+}
+
+func BenchmarkMixedArenaKeys(b *testing.B) {
+	benchmarkCache(b, func() *Cache { return newBenchCache().Prealloc().ArenaKeys(64 << 10) }, benchMixed)
+}
+
+func BenchmarkMixedTypedCache(b *testing.B) {
+	for _, g := range benchGoroutines {
+		b.Run(fmt.Sprintf("goroutines-%d", g), func(b *testing.B) {
+			c := NewTypedCache[string, int](AutoBuckets, benchKeyCnt, time.Hour)
+			for i, k := range benchKeys {
+				c.Put(k, i)
+			}
+			runGoroutines(b, g, func(i int) {
+				if k := benchKeys[i&(benchKeyCnt-1)]; i%10 == 0 {
+					c.Put(k, i)
+				} else {
+					c.Get(k)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+import "time"
+
+// measure cost of a value put without a cost, by length of string or []byte, 1 for others
+func costOf(v interface{}) int64 {
+	if n := sizeOfValue(v); n > 0 {
+		return int64(n)
+	}
+	return 1
+}
+
+// NewLRUCacheWithBudget - create lru cache whose buckets are bounded by total cost (e.g. bytes) of items instead of count,
+// for values varying from a few bytes to megabytes, least recent items are evicted until the new one fits
+// `maxCostPerBkt` is budget of each bucket, `.LFU(<num>)` then takes budget of each lfu bucket as well
+// items are measured by `PutWithCost`, or length of string or []byte (as stored) and 1 for others by the other puts,
+// items costing more than the budget are not stored, see `NewLRUCache` for the other parameters
+func NewLRUCacheWithBudget(bucketCnt int, maxCostPerBkt int64, expire time.Duration) *Cache {
+	c := NewLRUCache(bucketCnt, 0, expire)
+	for i := range c.insts {
+		c.insts[i][0] = createBudget[string, *wrapper](maxCostPerBkt)
+	}
+	return c
+}
+
+// PutWithCost - put a item into cache with `cost` (e.g. size in bytes), only used with `NewLRUCacheWithBudget`
+func (c *Cache) PutWithCost(key string, val interface{}, cost int64) {
+	c.put(key, val, 0, cost, FromPut)
+}
+
+// internal sub function that put item at specific level bounded by cost, lock of the bucket must be held
+func (c *Cache) setCost(key string, idx, level int, w *wrapper) {
+	inst := c.insts[idx][level]
+	if old, ok := inst.del(key); ok {
+		c.drop(key, idx, old, Replaced)
+	}
+	if w.cost > inst.budget { // never fits, e.g. demoted to a level of smaller budget
+		c.drop(key, idx, w, Evicted)
+		return
+	}
+	inst.trim(w.cost, func(k string, v *wrapper) { c.evicted(k, idx, level, v) })
+	inst.putCost(key, w, w.cost)
+}
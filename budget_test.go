@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_trim(t *testing.T) {
+	c := createBudget[string, interface{}](10)
+	c.putCost("1", "1", 4)
+	c.putCost("2", "2", 4)
+	c.putCost("1", "1", 2) // replaced
+	if c.used != 6 {
+		t.Error("case 1 failed")
+	}
+	var evicted []string
+	c.trim(9, func(k string, v interface{}) { evicted = append(evicted, k) })
+	if len(evicted) != 2 || evicted[0] != "2" || evicted[1] != "1" || c.used != 0 || c.length() != 0 {
+		t.Error("case 2 failed", evicted)
+	}
+	c.putCost("3", "3", 5)
+	c.del("3")
+	if c.used != 0 || c.capacity() != 0 || c.fresh().budget != 10 {
+		t.Error("case 3 failed")
+	}
+}
+
+func Test_NewLRUCacheWithBudget(t *testing.T) {
+	var evicted []string
+	lc := NewLRUCacheWithBudget(1, 10, time.Second).LFU(6).OnEvict(func(key string, _ interface{}, reason EvictReason) {
+		if reason == Evicted {
+			evicted = append(evicted, key)
+		}
+	})
+	lc.PutWithCost("1", "1", 3)
+	lc.PutWithCost("2", "2", 3)
+	lc.Put("3", "1234")
+	if s := lc.ShardStats(0); s.Len != 3 || s.Cost != 10 || s.Budget != 10 || s.Cap != 0 || s.LFUBudget != 6 {
+		t.Error("case 1 failed", s)
+	}
+	lc.PutWithCost("4", "4", 5) // evicts "1" and "2"
+	if len(evicted) != 2 || evicted[0] != "1" || evicted[1] != "2" {
+		t.Error("case 2 failed", evicted)
+	}
+	if s := lc.ShardStats(0); s.Len != 2 || s.Cost != 9 || s.Evictions != 2 {
+		t.Error("case 3 failed", s)
+	}
+
+	lc.PutWithCost("5", "5", 11) // never fits
+	if _, ok := lc.Get("5"); ok {
+		t.Error("case 4 failed")
+	}
+	lc.PutWithCost("4", "4", 11) // never fits, and the former one is gone
+	if _, ok := lc.Get("4"); ok {
+		t.Error("case 5 failed")
+	}
+
+	lc.Put("6", 6)
+	lc.Get("3") // l0 -> l1
+	lc.Get("6") // l0 -> l1, evicts nothing
+	lc.PutWithCost("7", "7", 3)
+	lc.Get("7") // l0 -> l1, evicts "3"
+	if s := lc.ShardStats(0); s.LFULen != 2 || s.LFUCost != 4 || s.Len != 0 {
+		t.Error("case 6 failed", s)
+	}
+	lc.PutWithCost("8", "8", 7)
+	lc.Get("8") // too large to promote
+	if s := lc.ShardStats(0); s.LFULen != 2 || s.Len != 1 {
+		t.Error("case 7 failed", s)
+	}
+
+	lc.ReplaceAll(map[string]interface{}{"1": "123456", "2": "123456"})
+	if s := lc.ShardStats(0); s.Len != 1 || s.Cost != 6 || s.LFULen != 0 {
+		t.Error("case 8 failed", s)
+	}
+	if s := lc.Prealloc().ShardStats(0); s.Len != 1 || s.Cost != 6 {
+		t.Error("case 9 failed", s)
+	}
+}
+
+func Test_budgetReject(t *testing.T) {
+	var evs, states []string
+	lc := NewLRUCacheWithBudget(1, 10, time.Second).Trace("*", 8).
+		KeyspaceEvents("*", func(e KeyspaceEvent) { evs = append(evs, e.Event+" "+e.Key) }).
+		OnState(func(key string, from, to State) { states = append(states, key+" "+from.String()+"->"+to.String()) })
+	lc.PutWithCost("1", "1", 3)
+	evs, states = nil, nil
+	lc.PutWithCost("1", "1", 11) // never fits, and the former one is deleted
+	if fmt.Sprint(evs) != "[del 1]" || fmt.Sprint(states) != "[1 ready->evicting]" {
+		t.Error("case 1 failed: ", evs, states)
+	}
+	if s := lc.Stats(); s.Puts != 1 || s.Len != 0 || s.Cost != 0 {
+		t.Error("case 2 failed: ", s.ShardStats)
+	}
+	if log := lc.TraceLog(); log[len(log)-1].Event != "rejected" {
+		t.Error("case 3 failed: ", log)
+	}
+	if lc.PutIfAbsent("2", "12345678901") {
+		t.Error("case 4 failed")
+	}
+	if lc.Import(Record{Key: "3", Value: "3", Cost: 11, TTL: -1}, 0) {
+		t.Error("case 5 failed")
+	}
+}
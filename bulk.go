@@ -0,0 +1,171 @@
+package cache
+
+import "sort"
+
+// Walk - call f sequentially for each live key and value, until f returns false, see `Range`
+func (c *Cache) Walk(f func(key string, val interface{}) bool) {
+	c.Range(func(e EntryView) bool {
+		return f(e.Key, e.Value)
+	})
+}
+
+// Len - count of items held by all buckets (and levels),
+// expired ones not removed yet are counted, so is a key held by both levels of lfu
+func (c *Cache) Len() (n int) {
+	for idx := range c.insts {
+		c.lock(idx)
+		for _, inst := range c.insts[idx] {
+			if inst != nil {
+				n += inst.length()
+			}
+		}
+		c.locks[idx].Unlock()
+	}
+	return
+}
+
+// Clear - delete all items, see `ReplaceAll`
+func (c *Cache) Clear() {
+	c.ReplaceAll(nil)
+}
+
+// Update - atomically replace the value of live `key` with what f returns, and report whether the key is live,
+// it's replaced in place, keeping the level, age, access frequency, `Source`, deadline set by `PutWithTTL`,
+// channel of `ExpireAfter` and cost of the item (`PutWithCost` again if the size changed much),
+// so updating a hot item doesn't reset its LFU state, and it's not reported to `OnEvict`, `KeyspaceEvents` or the other hooks,
+// f is called with the bucket locked, so it must not call back into the cache
+func (c *Cache) Update(key string, f func(v interface{}) interface{}) bool {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	if c.freezes[idx] != nil {
+		c.thawed(idx) // deferred writes may change the value
+	}
+	w, _ := c.peek(key, idx)
+	if w == nil {
+		c.locks[idx].Unlock()
+		return false
+	}
+	v, ok := w.v, true
+	if c.pipeline != nil && !c.dryRun {
+		if v, ok = c.pipeline.decode(v); ok {
+			v, ok = c.pipeline.encode(f(v))
+		}
+	} else {
+		v = f(v)
+	}
+	if c.dryRun {
+		v = nil // keys only
+	}
+	if ok {
+		if c.interns != nil {
+			v = c.intern(idx, v)
+		}
+		w.v = v // in place, it's not a departure of the item
+		w.enc = nil
+	} else {
+		c.remove(key, idx) // never serve the former value
+	}
+	c.locks[idx].Unlock()
+	if c.churn != nil {
+		c.churn.put(key)
+	}
+	return true
+}
+
+// order indices of `keys` by their buckets, and fill `idxs` with the bucket of each key
+func (c *Cache) byBucket(keys []string, idxs []int) []int {
+	order := make([]int, len(keys))
+	for i, k := range keys {
+		idxs[i], order[i] = hashCode(k)&c.mask, i
+	}
+	sort.Slice(order, func(i, j int) bool { return idxs[order[i]] < idxs[order[j]] })
+	return order
+}
+
+// MGet - get values of `keys`, only hits are in the result,
+// keys are grouped by buckets so that each bucket is locked only once
+func (c *Cache) MGet(keys ...string) map[string]interface{} {
+	res := make(map[string]interface{}, len(keys))
+	idxs, vals, hits := make([]int, len(keys)), make([]interface{}, len(keys)), make([]bool, len(keys))
+	order := c.byBucket(keys, idxs)
+	for i := 0; i < len(order); {
+		idx := idxs[order[i]]
+		c.lock(idx)
+		for ; i < len(order) && idxs[order[i]] == idx; i++ {
+			j := order[i]
+			if c.distinct != nil {
+				c.distinct.add(keys[j])
+			}
+			vals[j], hits[j] = c.lookup(keys[j], idx)
+		}
+		c.locks[idx].Unlock()
+	}
+	for j, k := range keys {
+		if hits[j] {
+			if v, ok := c.deliver(k, vals[j]); ok {
+				res[k] = v
+			}
+		}
+	}
+	return res
+}
+
+// MPut - put all `pairs` into cache,
+// keys are grouped by buckets so that each bucket is locked only once
+func (c *Cache) MPut(pairs map[string]interface{}) {
+	keys, vals := make([]string, 0, len(pairs)), make([]interface{}, 0, len(pairs))
+	for k, v := range pairs {
+		if c.dryRun {
+			v = nil // keys only
+		} else if c.pipeline != nil {
+			var ok bool
+			if v, ok = c.pipeline.encode(v); !ok {
+				c.Del(k) // never serve the former value
+				continue
+			}
+		}
+		keys, vals = append(keys, k), append(vals, v)
+	}
+	idxs := make([]int, len(keys))
+	order := c.byBucket(keys, idxs)
+	for i := 0; i < len(order); {
+		idx := idxs[order[i]]
+		c.lock(idx)
+		for ; i < len(order) && idxs[order[i]] == idx; i++ {
+			j := order[i]
+			if c.freezes[idx] != nil {
+				c.enqueue(deferred{key: keys[j], val: vals[j]}, idx)
+			} else {
+				c.store(keys[j], idx, vals[j], 0, 0, FromPut)
+			}
+		}
+		if c.sweep > 0 {
+			c.step(idx, c.sweep)
+		}
+		c.locks[idx].Unlock()
+	}
+	if c.churn != nil {
+		for _, k := range keys {
+			c.churn.put(k)
+		}
+	}
+}
+
+// MDel - delete items of `keys`, e.g. a burst of invalidations,
+// keys are grouped by buckets so that each bucket is locked only once
+func (c *Cache) MDel(keys ...string) {
+	idxs := make([]int, len(keys))
+	order := c.byBucket(keys, idxs)
+	for i := 0; i < len(order); {
+		idx := idxs[order[i]]
+		c.lock(idx)
+		for ; i < len(order) && idxs[order[i]] == idx; i++ {
+			if c.freezes[idx] != nil {
+				c.enqueue(deferred{key: keys[order[i]], del: true}, idx)
+			} else {
+				c.remove(keys[order[i]], idx)
+			}
+		}
+		c.locks[idx].Unlock()
+	}
+}
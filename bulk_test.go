@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_WalkLenClear(t *testing.T) {
+	lc := NewLRUCache(4, 100, 0)
+	for i := 0; i < 10; i++ {
+		lc.Put(strconv.Itoa(i), i)
+	}
+	if lc.Len() != 10 {
+		t.Error("case 1 failed")
+	}
+	sum, n := 0, 0
+	lc.Walk(func(key string, val interface{}) bool {
+		if strconv.Itoa(val.(int)) != key {
+			t.Error("case 2 failed: ", key, val)
+		}
+		sum += val.(int)
+		n++
+		return true
+	})
+	if sum != 45 || n != 10 {
+		t.Error("case 3 failed")
+	}
+	n = 0
+	lc.Walk(func(key string, val interface{}) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Error("case 4 failed")
+	}
+
+	lc.Clear()
+	if _, ok := lc.Get("1"); ok || lc.Len() != 0 {
+		t.Error("case 5 failed")
+	}
+	lc.Put("1", 1)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 6 failed")
+	}
+}
+
+func Test_Update(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).Clock(clk)
+	inc := func(v interface{}) interface{} { return v.(int) + 1 }
+	if lc.Update("1", inc) {
+		t.Error("case 1 failed")
+	}
+	lc.Put("1", 1)
+	if !lc.Update("1", inc) {
+		t.Error("case 2 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != 2 {
+		t.Error("case 3 failed")
+	}
+
+	lc.PutWithTTL("2", 1, 100*time.Millisecond)
+	lc.Update("2", inc)
+	clk.Add(101 * time.Millisecond)
+	if _, ok := lc.Get("2"); ok {
+		t.Error("case 4 failed")
+	}
+	if lc.Update("2", inc) {
+		t.Error("case 5 failed")
+	}
+
+	// in place
+	lc = NewLRUCache(1, 3, time.Second).LFU(3).Clock(clk)
+	lc.GetOrLoadWith("3", func(key string) (interface{}, error) { return 3, nil })
+	lc.Get("3") // l0 -> l1
+	clk.Add(500 * time.Millisecond)
+	lc.Update("3", inc)
+	var e EntryView
+	lc.Range(func(v EntryView) bool {
+		e = v
+		return true
+	})
+	if e.Value != 4 || e.Level != 1 || e.Source != FromLoader || e.Age != 500*time.Millisecond {
+		t.Error("case 6 failed: ", e)
+	}
+	clk.Add(501 * time.Millisecond)
+	if _, ok := lc.Get("3"); ok {
+		t.Error("case 7 failed")
+	}
+
+	// not a departure, and the cost is kept
+	evicts := 0
+	lc = NewLRUCacheWithBudget(1, 10, 0).OnEvict(func(key string, val interface{}, reason EvictReason) {
+		evicts++
+	})
+	lc.PutWithCost("4", "a", 6)
+	ch, _ := lc.ExpireAfter("4")
+	lc.Update("4", func(v interface{}) interface{} { return "abcdefgh" })
+	if v, ok := lc.Get("4"); !ok || v != "abcdefgh" || evicts != 0 || closed(ch, 0) {
+		t.Error("case 8 failed: ", v, evicts)
+	}
+	if s := lc.Stats(); s.Cost != 6 || s.Puts != 1 {
+		t.Error("case 9 failed: ", s.Cost, s.Puts)
+	}
+	lc.Put("5", "abcde") // evicts "4" by cost 6 + 5
+	if _, ok := lc.Get("4"); ok || evicts != 1 {
+		t.Error("case 10 failed")
+	}
+}
+
+func Test_MGetMPut(t *testing.T) {
+	lc := NewLRUCache(4, 100, 0).LFU(10)
+	pairs := map[string]interface{}{}
+	for i := 0; i < 50; i++ {
+		pairs[strconv.Itoa(i)] = i
+	}
+	lc.MPut(pairs)
+	if lc.Len() != 50 {
+		t.Error("case 1 failed")
+	}
+	keys := []string{"none"}
+	for i := 0; i < 60; i += 2 {
+		keys = append(keys, strconv.Itoa(i))
+	}
+	res := lc.MGet(keys...)
+	if len(res) != 25 {
+		t.Error("case 2 failed: ", len(res))
+	}
+	for k, v := range res {
+		if strconv.Itoa(v.(int)) != k {
+			t.Error("case 3 failed: ", k, v)
+		}
+	}
+	if lc.Stats().Hits != 25 || lc.Stats().Misses != 6 || lc.Stats().LFULen != 25 {
+		t.Error("case 4 failed")
+	}
+	if len(lc.MGet()) != 0 {
+		t.Error("case 5 failed")
+	}
+}
+
+func Test_MDel(t *testing.T) {
+	lc := NewLRUCache(4, 100, 0).LFU(10)
+	for i := 0; i < 50; i++ {
+		lc.Put(strconv.Itoa(i), i)
+	}
+	lc.Get("0") // l0 -> l1
+	keys := []string{"none"}
+	for i := 0; i < 50; i += 2 {
+		keys = append(keys, strconv.Itoa(i))
+	}
+	lc.MDel(keys...)
+	if lc.Len() != 25 {
+		t.Error("case 1 failed")
+	}
+	if _, ok := lc.Get("0"); ok {
+		t.Error("case 2 failed")
+	}
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 3 failed")
+	}
+
+	lc.FreezeShard(hashCode("1") & lc.mask)
+	lc.MDel("1")
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 4 failed")
+	}
+	lc.Thaw(hashCode("1") & lc.mask)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 5 failed")
+	}
+	lc.MDel()
+}
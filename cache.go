@@ -2,34 +2,74 @@ package cache
 
 import (
 	"hash/crc32"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 // node to store cache item
-type node struct {
-	p, n *node
-	k    string
-	v    interface{}
+type node[K comparable, V any] struct {
+	p, n *node[K, V]
+	k    K
+	v    V
+	cost int64
 }
 
 // a data structure that is efficient to insert/fetch/delete cache items [both O(1) time complexity]
-type cache struct {
-	cap  int
-	hmap map[interface{}]*node
-	head *node // not use pointer-to-pointer here,
-	tail *node // coz it's trade-off for performance
+// it's generic so values are stored as they are, `Cache` stores wrappers of boxed values, `Typed` stores typed values
+type cache[K comparable, V any] struct {
+	cap    int
+	hmap   map[K]*node[K, V]
+	head   *node[K, V] // not use pointer-to-pointer here,
+	tail   *node[K, V] // coz it's trade-off for performance
+	cur    *node[K, V] // cursor of amortized sweep, walks from tail to head
+	free   *node[K, V] // list of removed nodes for reuse, only if preallocated
+	pool   bool
+	arena  *arena // copies of keys, only for string keys, see `ArenaKeys`
+	used   int64  // total cost of items
+	budget int64  // max total cost of items, 0 to bound count of items by `cap` instead
 }
 
 // create a new lru cache object
-func create(cap int) *cache {
-	return &cache{cap, make(map[interface{}]*node, cap), nil, nil}
+func create[K comparable, V any](cap int) *cache[K, V] {
+	return &cache[K, V]{cap: cap, hmap: make(map[K]*node[K, V], cap)}
 }
 
-// put a cache item into lru cache
-func (c *cache) put(k string, v interface{}) {
+// create a new lru cache object bounded by total cost of items
+func createBudget[K comparable, V any](budget int64) *cache[K, V] {
+	return &cache[K, V]{cap: math.MaxInt, hmap: make(map[K]*node[K, V]), budget: budget}
+}
+
+// create a new empty lru cache object with the same bounds
+func (c *cache[K, V]) fresh() *cache[K, V] {
+	var n *cache[K, V]
+	if c.budget > 0 {
+		n = createBudget[K, V](c.budget)
+	} else {
+		n = create[K, V](c.cap)
+	}
+	if c.pool { // keep reusing nodes after `ReplaceAll`
+		n.prealloc()
+	}
+	if c.arena != nil {
+		n.arena = &arena{size: c.arena.size}
+	}
+	return n
+}
+
+// put a cache item into lru cache, returns the value replaced or evicted (and key of the tail item if it's evicted)
+func (c *cache[K, V]) put(k K, v V) (old V, evictedKey K, evicted bool) {
+	return c.putCost(k, v, 0)
+}
+
+// put a cache item with cost, see `put`
+func (c *cache[K, V]) putCost(k K, v V, cost int64) (old V, evictedKey K, evicted bool) {
 	if e, ok := c.hmap[k]; ok {
-		e.v = v
+		old, e.v = e.v, v
+		c.used += cost - e.cost
+		e.cost = cost
 		c._refresh(e)
 		return
 	}
@@ -39,13 +79,34 @@ func (c *cache) put(k string, v interface{}) {
 	} else if len(c.hmap) >= c.cap {
 		// transfer the tail item as the new item, then refresh
 		delete(c.hmap, c.tail.k)
-		c.tail.k, c.tail.v = k, v // reuse to reduce gc
+		if c.cur == c.tail {
+			c.cur = c.tail.p
+		}
+		old, evictedKey = c.tail.v, c.tail.k
+		c.used += cost - c.tail.cost
+		if c.arena != nil {
+			k = c.own(k)
+		}
+		c.tail.k, c.tail.v, c.tail.cost = k, v, cost // reuse to reduce gc
 		c.hmap[k] = c.tail
 		c._refresh(c.tail)
-		return
+		if c.arena != nil {
+			c.compact()
+		}
+		return old, evictedKey, true
 	}
 
-	e := &node{nil, c.head, k, v}
+	e := c.free
+	if e != nil {
+		c.free = e.n
+	} else {
+		e = &node[K, V]{}
+	}
+	if c.arena != nil {
+		k = c.own(k)
+	}
+	e.p, e.n, e.k, e.v, e.cost = nil, c.head, k, v, cost
+	c.used += cost
 	c.hmap[k] = e
 	if len(c.hmap) != 1 {
 		c.head.p = e
@@ -53,29 +114,36 @@ func (c *cache) put(k string, v interface{}) {
 		c.tail = e
 	}
 	c.head = e
+	if c.arena != nil {
+		c.compact()
+	}
+	return
 }
 
 // get value of key from lru cache with result
-func (c *cache) get(k string) (interface{}, bool) {
+func (c *cache[K, V]) get(k K) (v V, ok bool) {
 	if e, ok := c.hmap[k]; ok {
 		c._refresh(e)
 		return e.v, ok
 	}
-	return nil, false
+	return
 }
 
 // delete item by key from lru cache
-func (c *cache) del(k string) (interface{}, bool) {
+func (c *cache[K, V]) del(k K) (v V, ok bool) {
 	if e, ok := c.hmap[k]; ok {
 		delete(c.hmap, k)
 		c._remove(e)
-		return e.v, true
+		c.used -= e.cost
+		v = e.v
+		c._free(e)
+		return v, true
 	}
-	return nil, false
+	return
 }
 
 // calls f sequentially for each key and value present in the lru cache
-func (c *cache) foreach(f func(k string, v interface{}) bool) {
+func (c *cache[K, V]) foreach(f func(k K, v V) bool) {
 	for i := c.head; i != nil; i = i.n {
 		if !f(i.k, i.v) {
 			break
@@ -84,27 +152,92 @@ func (c *cache) foreach(f func(k string, v interface{}) bool) {
 }
 
 // inplace update
-func (c *cache) update(k string, f func(v *interface{})) {
+func (c *cache[K, V]) update(k K, f func(v *V)) {
 	if e, ok := c.hmap[k]; ok {
 		f(&e.v)
 		c._refresh(e)
 	}
 }
 
+// walk at most n items from the sweep cursor, delete the ones that f reports, returns count of deleted items
+func (c *cache[K, V]) sweep(n int, f func(k K, v V) bool) (cnt int) {
+	if n > len(c.hmap) {
+		n = len(c.hmap)
+	}
+	for ; n > 0; n-- {
+		if c.cur == nil { // wrap around
+			c.cur = c.tail
+		}
+		e := c.cur
+		c.cur = e.p
+		if f(e.k, e.v) {
+			delete(c.hmap, e.k)
+			c._remove(e)
+			c.used -= e.cost
+			c._free(e)
+			cnt++
+		}
+	}
+	return
+}
+
+// evict items from the tail until an item of `cost` fits the budget, f is called for each evicted item
+func (c *cache[K, V]) trim(cost int64, f func(k K, v V)) {
+	for c.used+cost > c.budget && c.tail != nil {
+		e := c.tail
+		delete(c.hmap, e.k)
+		c._remove(e)
+		c.used -= e.cost
+		if f != nil {
+			f(e.k, e.v)
+		}
+		c._free(e)
+	}
+}
+
 // length of lru cache
-func (c *cache) length() int {
+func (c *cache[K, V]) length() int {
 	return len(c.hmap)
 }
 
-// capacity of lru cache
-func (c *cache) capacity() int {
+// capacity of lru cache, 0 if it's bounded by budget
+func (c *cache[K, V]) capacity() int {
+	if c.budget > 0 {
+		return 0
+	}
 	return c.cap
 }
 
-func (c *cache) _refresh(e *node) {
+// preallocate all nodes in one slab, and reuse removed ones
+func (c *cache[K, V]) prealloc() {
+	if c.cap <= len(c.hmap) || c.budget > 0 { // nodes are allocated on demand with budget
+		c.pool = true
+		return
+	}
+	slab := make([]node[K, V], c.cap-len(c.hmap))
+	for i := range slab {
+		slab[i].n, c.free = c.free, &slab[i]
+	}
+	c.pool = true
+}
+
+func (c *cache[K, V]) _free(e *node[K, V]) {
+	if c.pool {
+		var (
+			k K
+			v V
+		)
+		e.p, e.n, e.k, e.v, c.free = nil, c.free, k, v, e
+	}
+}
+
+func (c *cache[K, V]) _refresh(e *node[K, V]) {
 	if e.p == nil { // head node
 		return
 	}
+	if c.cur == e {
+		c.cur = e.p
+	}
 	e.p.n = e.n
 	if e.n == nil { // tail node
 		c.tail = e.p
@@ -114,7 +247,10 @@ func (c *cache) _refresh(e *node) {
 	e.p, e.n, c.head.p, c.head = nil, c.head, e, e
 }
 
-func (c *cache) _remove(e *node) {
+func (c *cache[K, V]) _remove(e *node[K, V]) {
+	if c.cur == e {
+		c.cur = e.p
+	}
 	if e.p == nil { // head node
 		c.head = e.n
 	} else {
@@ -129,21 +265,96 @@ func (c *cache) _remove(e *node) {
 
 // hashCode hashes a string to a unique hashcode.
 func hashCode(s string) int {
-	return int(crc32.ChecksumIEEE([]byte(s)))
+	// view the string as []byte without copying, it's read only
+	return int(crc32.ChecksumIEEE(*(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))))
 }
 
+// Interface - basic operations of cache, for decorators and mocks, `*Cache` implements it
+type Interface interface {
+	Put(key string, val interface{})
+	Get(key string) (interface{}, bool)
+	Del(key string)
+}
+
+var _ Interface = (*Cache)(nil)
+
 // Cache - concurrent cache structure
 type Cache struct {
-	locks  []sync.Mutex
-	insts  [][2]*cache // level-0 for normal LRU, level-1 for LFU-2
-	mask   int
-	expire time.Duration
+	sweepSkips  uint64    // buckets skipped by the janitor, first for 64-bit alignment of atomic operations
+	evictDrops  uint64    // evictions dropped by `OnEvictAsync`
+	locks       []slot    // with counters of each bucket
+	insts       [][2]*lru // level-0 for normal LRU, level-1 for LFU-2
+	mask        int
+	expire      [2]time.Duration // expiration of level-0 and level-1
+	sweep       int
+	clock       Clock
+	decay       *decay
+	churn       *churn
+	pipeline    *pipeline
+	shadow      *shadow
+	watched     int32 // whether `ExpireAfter` is ever called
+	interns     []map[interface{}]interface{}
+	internCap   int
+	dryRun      bool
+	tombs       []side[tomb]
+	tombWindow  time.Duration
+	trash       []side[trashed] // items removed by `SoftDel`, see `SoftDelete`
+	trashWindow time.Duration
+	access      []map[string]*access
+	accessCap   int
+	waiters     []map[string]*waiter // goroutines blocked in `Await`
+	victims     []*victim
+	freezes     []*freeze             // writes are deferred while a bucket is frozen, see `FreezeShard`
+	admit       func(w *wrapper) bool // whether the item can be promoted to level-1
+	distinct    *hll
+	onEvict     func(key string, val interface{}, reason EvictReason)
+	onEvictTag  func(key string, val interface{}, reason EvictReason, tag interface{})
+	tags        []interface{} // tag of the write in progress of each bucket, see `WithTag`
+	janitor     *janitor
+	sweepBgt    sweepBudget        // of the janitor, see `JanitorBudget`
+	archiver    *archiver          // see `Archive`
+	evictQ      *evictQ            // see `OnEvictAsync`
+	calls       []map[string]*call // in-flight loads of `GetOrLoadWith`
+	onState     func(key string, from, to State)
+	lfuExpired  ExpiredPolicy // see `LFUExpired`
+	refresh     func(key string) (interface{}, error)
+	limits      []refreshLimit  // by length of prefix in descending order, see `RefreshLimit`
+	fills       []side[int64]   // when keys are loaded last time
+	adaptive    *adaptive       // see `AdaptiveTTL`
+	predictor   *predictor      // see `Prefetch`
+	subs        []subscription  // see `KeyspaceEvents`
+	tracer      *tracer         // see `Trace`
+	errs        []side[loadErr] // errors of loaders cached by `LoadErrorTTL`
+	errTTL      time.Duration
+	doErrors    bool         // see `DoErrors`
+	wrappers    [][]*wrapper // pools of wrappers for reuse, see `Prealloc`
+	keepEnc     bool         // see `KeepEncoded`
+	carryMu     sync.Mutex
+	carried     ShardStats // counters of former processes, see `AddCarryover`
 }
 
 // the wrapper is necessary because of node reuse otherwise it's not threadsafe
 type wrapper struct {
-	v  interface{}
-	ts int64 // nano timestamp
+	v     interface{}
+	ts    int64   // nano timestamp
+	fts   int64   // nano timestamp of last frequency update
+	freq  float64 // decayed access frequency, only used with `Decay`
+	ver   int64   // version (nano timestamp) given by `PutIfNewer`
+	exp   int64   // nano timestamp of expiration given by `PutWithTTL`, 0 for `expire` of the level
+	cost  int64   // given by `PutWithCost`, only used with `NewLRUCacheWithBudget`
+	watch *watch  // created by `ExpireAfter`
+	src   Source
+	enc   *atomic.Value // bytes of the value encoded by `Record.Encode`, see `KeepEncoded`
+}
+
+// a level of a bucket of `Cache`
+type lru = cache[string, *wrapper]
+
+func newWrapper(v interface{}, now int64) *wrapper {
+	return &wrapper{v: v, ts: now, fts: now, freq: 1}
 }
 
 func nextPowOf2(cap int) int {
@@ -162,15 +373,22 @@ func nextPowOf2(cap int) int {
 }
 
 // NewLRUCache - create lru cache
-// `bucketCnt` is buckets that shard items to reduce lock racing
+// `bucketCnt` is buckets that shard items to reduce lock racing, `AutoBuckets` to use a multiple of GOMAXPROCS
 // `capPerBkt` is length of each bucket
 // can store `capPerBkt * bucketCnt` count of element in Cache at most
-// `expire` is expiration that item alive (and we only use lazy eviction here)
+// `expire` is expiration that item alive (and we only use lazy eviction here, see `Sweep` for active expiration)
+// it's measured by a monotonic clock in nanoseconds, so sub-millisecond (down to 1ns) expirations are honored,
+// an item is alive while the time elapsed since it was put is not longer than `expire`
+// `0` (or negative) means items never expire, then `Get` skips reading the clock at all
 func NewLRUCache(bucketCnt int, capPerBkt int, expire time.Duration) *Cache {
+	if bucketCnt == AutoBuckets {
+		bucketCnt = autoBuckets()
+	}
 	size := nextPowOf2(bucketCnt)
-	c := &Cache{make([]sync.Mutex, size), make([][2]*cache, size), size - 1, expire}
+	c := &Cache{waiters: make([]map[string]*waiter, size), freezes: make([]*freeze, size), calls: make([]map[string]*call, size), tags: make([]interface{}, size), insts: make([][2]*lru, size), mask: size - 1, expire: [2]time.Duration{expire, expire}, clock: sysClock{}}
+	c.locks = make([]slot, size)
 	for i := range c.insts {
-		c.insts[i][0] = create(capPerBkt)
+		c.insts[i][0] = create[string, *wrapper](capPerBkt)
 	}
 	return c
 }
@@ -180,67 +398,390 @@ func NewLRUCache(bucketCnt int, capPerBkt int, expire time.Duration) *Cache {
 // can store extra `capPerBkt * bucketCnt` count of element in Cache at most
 func (c *Cache) LFU(capPerBkt int) *Cache {
 	for i := range c.insts {
-		c.insts[i][1] = create(capPerBkt)
+		if c.insts[i][0].budget > 0 {
+			c.insts[i][1] = createBudget[string, *wrapper](int64(capPerBkt))
+		} else {
+			c.insts[i][1] = create[string, *wrapper](capPerBkt)
+		}
 	}
 	return c
 }
 
+// LFUExpire - set expiration of items in upper-level-cache, which is the same as `expire` of `NewLRUCache` by default
+// promoted items have demonstrated their value, so they may deserve a longer freshness window
+// it counts from when the item was put, not when it was promoted
+func (c *Cache) LFUExpire(expire time.Duration) *Cache {
+	c.expire[1] = expire
+	return c
+}
+
 // Put - put a item into cache
 func (c *Cache) Put(key string, val interface{}) {
+	c.put(key, val, 0, 0, FromPut)
+}
+
+// internal sub function that put a item expiring at `exp` (0 for `expire` of the level), with `cost` (0 to measure it),
+// returns the freeze deferring the write, nil if it's applied
+func (c *Cache) put(key string, val interface{}, exp, cost int64, src Source) (f *freeze) {
+	return c.putTagged(key, val, exp, cost, src, nil)
+}
+
+// internal sub function that put a item with the tag of the caller (see `WithTag`)
+func (c *Cache) putTagged(key string, val interface{}, exp, cost int64, src Source, tag interface{}) (f *freeze) {
+	if c.dryRun {
+		val = nil // keys only
+	} else if c.pipeline != nil {
+		var ok bool
+		if val, ok = c.pipeline.encode(val); !ok {
+			c.Del(key) // never serve the former value
+			return nil
+		}
+	}
 	idx := hashCode(key) & c.mask
-	c.locks[idx].Lock()
-	c.insts[idx][0].put(key, &wrapper{val, time.Now().UnixNano()})
+	c.lock(idx)
+	if c.freezes[idx] != nil {
+		f = c.enqueue(deferred{key: key, val: val, exp: exp, cost: cost, src: src, tag: tag}, idx)
+	} else {
+		c.tags[idx] = tag
+		c.store(key, idx, val, exp, cost, src)
+		c.tags[idx] = nil
+	}
+	if c.sweep > 0 {
+		c.step(idx, c.sweep)
+	}
 	c.locks[idx].Unlock()
+	if c.churn != nil {
+		c.churn.put(key)
+	}
+	return f
+}
+
+// internal sub function that put a item at level-0, lock of the bucket must be held
+func (c *Cache) store(key string, idx int, val interface{}, exp, cost int64, src Source) {
+	if c.tombs != nil && c.buried(key, idx, 0) {
+		return
+	}
+	if c.interns != nil {
+		val = c.intern(idx, val)
+	}
+	w := c.alloc(idx, val, c.now())
+	w.exp, w.cost, w.src = exp, cost, src
+	c.insert(key, idx, 0, w)
+}
+
+// internal sub function that put a new item at `level`, lock of the bucket must be held,
+// returns false if it's rejected for costing more than the budget of the level, then the former item is deleted
+func (c *Cache) insert(key string, idx, level int, w *wrapper) bool {
+	from := Absent
+	if c.onState != nil {
+		from = c.stateOf(key, idx)
+	}
+	if budget := c.insts[idx][level].budget; budget > 0 {
+		if w.cost <= 0 {
+			w.cost = costOf(w.v)
+		}
+		if w.cost > budget { // never fits
+			c.reject(key, idx, w)
+			return false
+		}
+	}
+	if c.trash != nil {
+		if t := c.trash[idx].m[key]; t.w == w { // brought back by `Restore`
+			c.trash[idx].del(key)
+		} else {
+			c.discard(key, idx, Replaced)
+		}
+	}
+	c.set(key, idx, level, w)
+	c.locks[idx].cnts.puts++
+	c.locks[idx].cnts.installs[w.src]++
+	if len(c.waiters[idx]) != 0 {
+		c.wake(key, idx)
+	}
+	if c.onState != nil {
+		c.transit(key, from, Ready)
+	}
+	if c.subs != nil {
+		c.notify("set", key, c.tags[idx])
+		if w.exp != 0 && w.exp != never {
+			c.notify("expire", key, c.tags[idx])
+		}
+	}
+	if c.tracer != nil {
+		c.tracer.record("set", key, w.src, c.tags[idx])
+	}
+	return true
+}
+
+// reject the new item of key, and delete the former one so it's never served, lock of the bucket must be held
+func (c *Cache) reject(key string, idx int, w *wrapper) {
+	for _, inst := range c.insts[idx] {
+		if inst == nil {
+			continue
+		}
+		if v, ok := inst.del(key); ok {
+			c.drop(key, idx, v, Deleted)
+		}
+	}
+	if c.trash != nil {
+		c.discard(key, idx, Deleted)
+	}
+	if c.tracer != nil {
+		c.tracer.record("rejected", key, w.src, c.tags[idx])
+	}
+}
+
+// internal sub function that put item at specific level, lock of the bucket must be held
+func (c *Cache) set(key string, idx, level int, w *wrapper) {
+	if c.insts[idx][level].budget > 0 {
+		c.setCost(key, idx, level, w)
+		return
+	}
+	if c.victims != nil {
+		c.evict(key, idx, level)
+	}
+	if old, evictedKey, evicted := c.insts[idx][level].put(key, w); evicted {
+		c.evicted(evictedKey, idx, level, old)
+	} else if old != nil {
+		c.drop(key, idx, old, Replaced)
+	}
+}
+
+// called when the item is evicted by capacity
+func (c *Cache) evicted(key string, idx, level int, w *wrapper) {
+	c.locks[idx].cnts.evictions++
+	reason := Evicted
+	if (c.departs() || c.archiver != nil) && c.expired(w, c.clock.Now(), level) {
+		reason = Expired
+	}
+	if c.archiver != nil && reason == Evicted {
+		c.archive(key, idx, w) // before the wrapper is recycled by `drop`
+	}
+	c.drop(key, idx, w, reason)
+}
+
+// whether anything hears about items leaving the cache, i.e. `drop` has more to do than recycling wrappers
+func (c *Cache) departs() bool {
+	return atomic.LoadInt32(&c.watched) != 0 || c.onEvict != nil || c.onEvictTag != nil || c.evictQ != nil ||
+		c.onState != nil || c.subs != nil || c.tracer != nil
+}
+
+// called when the item leaves cache
+func (c *Cache) drop(key string, idx int, w *wrapper, reason EvictReason) {
+	from := Ready
+	if reason == Expired {
+		from = Stale
+	}
+	c.dropFrom(key, idx, w, reason, from)
+}
+
+// called when the item leaves cache from state `from`, e.g. `Hidden` for soft-deleted ones
+func (c *Cache) dropFrom(key string, idx int, w *wrapper, reason EvictReason, from State) {
+	if w.watch != nil {
+		w.watch.close()
+	}
+	if c.onEvict != nil {
+		c.onEvict(key, w.v, reason)
+	}
+	if c.onEvictTag != nil {
+		c.onEvictTag(key, w.v, reason, c.tags[idx])
+	}
+	if c.evictQ != nil {
+		c.handOver(key, w.v, reason)
+	}
+	if c.onState != nil && reason != Replaced {
+		c.transit(key, from, Evicting)
+	}
+	if c.subs != nil && reason != Replaced {
+		c.notify(reason.event(), key, c.tags[idx])
+	}
+	if c.tracer != nil {
+		c.tracer.record(reason.String(), key, 0, c.tags[idx])
+	}
+	if c.wrappers != nil && w.watch == nil { // watched ones are still referred by timers
+		if pool := c.wrappers[idx]; len(pool) < cap(pool) {
+			*w = wrapper{}
+			c.wrappers[idx] = append(pool, w)
+		}
+	}
+}
+
+// lock the bucket, and record the time waited if it's contended
+func (c *Cache) lock(idx int) {
+	if !c.locks[idx].TryLock() {
+		t := time.Now()
+		c.locks[idx].Lock()
+		c.locks[idx].cnts.waits++
+		c.locks[idx].cnts.waitNs += int64(time.Since(t))
+	}
 }
 
 // internal sub function that get item at specific level
-func (c *Cache) get(key string, idx, level int) (interface{}, bool) {
-	if v, b := c.insts[idx][level].get(key); b {
-		if time.Since(time.Unix(0, v.(*wrapper).ts)) > c.expire {
+func (c *Cache) get(key string, idx, level int) (*wrapper, bool) {
+	if w, b := c.insts[idx][level].get(key); b {
+		if c.mortal(w, level) && c.expired(w, c.clock.Now(), level) {
 			// we don't need to remove the expired item here
 			// removal is also ok that control the memory usage before the cache is full, but will cause GC thrashing
 			// c.insts[idx][level].del(key)
-			return v, false
+			c.locks[idx].cnts.expired++
+			return w, false
 		}
-		return v, b
+		return w, b
 	}
 	return nil, false
 }
 
+// whether the item at specific level is expired at `now`
+func (c *Cache) expired(w *wrapper, now int64, level int) bool {
+	if w.exp != 0 {
+		return now > w.exp
+	}
+	return c.expire[level] > 0 && now-w.ts > int64(c.expire[level])
+}
+
+// whether the item at specific level expires at all, so the clock can be skipped
+func (c *Cache) mortal(w *wrapper, level int) bool {
+	if w.exp != 0 {
+		return w.exp != never
+	}
+	return c.expire[level] > 0
+}
+
+// timestamp for a new item, skip reading the clock if nothing needs it
+func (c *Cache) now() int64 {
+	if c.expire[0] <= 0 && c.expire[1] <= 0 && c.decay == nil {
+		return 0
+	}
+	return c.clock.Now()
+}
+
 // Get - get value of key from cache with result
 // if the item is expired, maybe you can also get the former item even if it returns `false`
 func (c *Cache) Get(key string) (v interface{}, b bool) {
+	if c.distinct != nil {
+		c.distinct.add(key)
+	}
 	idx := hashCode(key) & c.mask
-	c.locks[idx].Lock()
+	c.lock(idx)
+	v, b = c.lookup(key, idx)
+	c.locks[idx].Unlock()
+	if c.predictor != nil {
+		c.predict(key, b)
+	}
+	if !b {
+		return nil, false
+	}
+	return c.deliver(key, v)
+}
+
+// look up `key` in bucket `idx` with the bucket locked, returns the stored (encoded) value
+func (c *Cache) lookup(key string, idx int) (v interface{}, b bool) {
+	var w *wrapper
 	if c.insts[idx][1] == nil { // (if lfu mode not support, loss is little)
 		// normal lru mode
-		v, b = c.get(key, idx, 0)
+		w, b = c.get(key, idx, 0)
+	} else if c.freezes[idx] != nil {
+		// frozen, promotion may evict items of level-1
+		if w, _ = c.peek(key, idx); w != nil {
+			b = true
+		}
+	} else if c.decay != nil {
+		// lfu with decayed frequency
+		w, b = c.getDecay(key, idx)
 	} else {
 		// lfu-2 mode
-		v, b = c.insts[idx][0].del(key)
+		w, b = c.insts[idx][0].del(key)
 		if !b {
 			// re-find in level-1
-			v, b = c.get(key, idx, 1)
+			if w, b = c.get(key, idx, 1); !b && w != nil && c.lfuExpired != KeepExpired {
+				c.expiredLFU(key, idx, w)
+			}
+		} else if c.mortal(w, 0) && c.expired(w, c.clock.Now(), 0) {
+			// expired in level-0, don't promote it
+			c.drop(key, idx, w, Expired)
+			c.locks[idx].cnts.expired++
+			b = false
+		} else if !c.promotable(idx, w) {
+			// too large, put it back
+			c.set(key, idx, 0, w)
 		} else {
 			// find in level-0, move to level-1
-			c.insts[idx][1].put(key, v.(*wrapper))
+			c.set(key, idx, 1, w)
+			if c.tracer != nil {
+				c.tracer.record("promote", key, 0, nil)
+			}
 		}
 	}
+	if c.sweep > 0 {
+		c.step(idx, c.sweep)
+	}
+	if c.access != nil {
+		c.track(key, idx, b)
+	}
 	if !b {
-		c.locks[idx].Unlock()
+		c.locks[idx].cnts.misses++
 		return nil, false
 	}
-	c.locks[idx].Unlock()
-	return v.(*wrapper).v, b
+	c.locks[idx].cnts.hits++
+	if c.predictor != nil && w.src == FromPrefetch {
+		atomic.AddUint64(&c.predictor.stats.Hits, 1)
+	}
+	return w.v, true // the wrapper may be reused once unlocked, see `Prealloc`
+}
+
+// the value of a hit to return to caller, called without lock
+func (c *Cache) deliver(key string, v interface{}) (interface{}, bool) {
+	if c.dryRun {
+		return nil, false // would have been a hit
+	}
+	if c.pipeline != nil {
+		var b bool
+		if v, b = c.pipeline.decode(v); !b {
+			return nil, false
+		}
+	}
+	if c.churn != nil {
+		c.churn.get(key)
+	}
+	if c.shadow != nil {
+		c.shadow.sample(key, v)
+	}
+	return v, true
 }
 
 // Del - delete item by key from cache
 func (c *Cache) Del(key string) {
+	c.del(key, nil)
+}
+
+// internal sub function that delete a item with the tag of the caller (see `WithTag`)
+func (c *Cache) del(key string, tag interface{}) {
 	idx := hashCode(key) & c.mask
-	c.locks[idx].Lock()
-	c.insts[idx][0].del(key)
-	if c.insts[idx][1] != nil { // (if lfu mode not support, loss is little)
-		c.insts[idx][1].del(key)
+	c.lock(idx)
+	if c.freezes[idx] != nil {
+		c.enqueue(deferred{key: key, del: true, tag: tag}, idx)
+	} else {
+		c.tags[idx] = tag
+		c.remove(key, idx)
+		c.tags[idx] = nil
 	}
 	c.locks[idx].Unlock()
 }
+
+// internal sub function that delete item at both levels, lock of the bucket must be held
+func (c *Cache) remove(key string, idx int) {
+	for _, inst := range c.insts[idx] {
+		if inst == nil { // (if lfu mode not support, loss is little)
+			continue
+		}
+		if v, ok := inst.del(key); ok {
+			c.drop(key, idx, v, Deleted)
+		}
+	}
+	if c.trash != nil {
+		c.discard(key, idx, Deleted)
+	}
+	if c.tombs != nil {
+		c.bury(key, idx)
+	}
+}
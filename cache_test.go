@@ -13,20 +13,20 @@ type Elem struct {
 }
 
 func Test_create(t *testing.T) {
-	c := create(5)
+	c := create[string, interface{}](5)
 	if c.length() != 0 {
 		t.Error("case 1 failed")
 	}
 }
 
 func Test_put(t *testing.T) {
-	c := create(0)
+	c := create[string, interface{}](0)
 	c.put("1", "1")
 	if c.length() != 0 {
 		t.Error("case 1.1 failed")
 	}
 
-	c = create(5)
+	c = create[string, interface{}](5)
 	c.put("1", "1")
 	c.put("2", "2")
 	c.put("1", "3")
@@ -122,7 +122,7 @@ func Test_put(t *testing.T) {
 }
 
 func Test_get(t *testing.T) {
-	c := create(2)
+	c := create[string, interface{}](2)
 	c.put("1", "1")
 	c.put("2", "2")
 	if v, _ := c.get("1"); v != "1" {
@@ -151,7 +151,7 @@ func Test_get(t *testing.T) {
 }
 
 func Test_delete(t *testing.T) {
-	c := create(5)
+	c := create[string, interface{}](5)
 	c.put("3", "4")
 	c.put("4", "5")
 	c.put("5", "6")
@@ -225,7 +225,7 @@ func Test_delete(t *testing.T) {
 }
 
 func Test_foreach(t *testing.T) {
-	c := create(5)
+	c := create[string, interface{}](5)
 	c.put("3", "4")
 	c.put("4", "5")
 	c.put("5", "6")
@@ -392,3 +392,48 @@ func Test_concurrentLFU(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func Test_LFUExpire(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).LFU(3).LFUExpire(time.Minute).Clock(clk)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Get("1") // l0 -> l1
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 1 failed")
+	}
+	if _, ok := lc.Get("2"); ok { // expired in l0, not promoted
+		t.Error("case 2 failed")
+	}
+	if _, ok := lc.Get("2"); ok {
+		t.Error("case 3 failed")
+	}
+	clk.Add(time.Minute)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 4 failed")
+	}
+}
+
+func Test_NoTTL(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, 0).LFU(3).Clock(clk)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Get("1") // l0 -> l1
+	clk.Add(time.Hour)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 1 failed")
+	}
+	if _, ok := lc.Get("2"); !ok {
+		t.Error("case 2 failed")
+	}
+	ch, ok := lc.ExpireAfter("1")
+	if !ok {
+		t.Error("case 3 failed")
+	}
+	lc.Del("1")
+	if !closed(ch, time.Second) {
+		t.Error("case 4 failed")
+	}
+}
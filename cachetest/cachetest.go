@@ -0,0 +1,99 @@
+// Package cachetest provides fixtures for tests of code that uses cache,
+// so they can start with realistic cache state and simulate time passing.
+package cachetest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/orca-zhang/cache"
+)
+
+// Clock - fake clock that only moves when told to
+type Clock struct {
+	ns int64
+}
+
+// Now - implements cache.Clock
+func (c *Clock) Now() int64 {
+	return atomic.LoadInt64(&c.ns)
+}
+
+// Add - simulate time passing
+func (c *Clock) Add(d time.Duration) {
+	atomic.AddInt64(&c.ns, int64(d))
+}
+
+// Duration - time.Duration in form of "300ms", "1.5h" or "2h45m" in fixture files
+type Duration time.Duration
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	*d = Duration(v)
+	return err
+}
+
+// Entry - an item in fixture file
+type Entry struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"` // decoded by encoding/json
+	Age   Duration    `json:"age"`   // how long ago the item was put
+}
+
+// Fixture - content of fixture file, parameters are the same as `cache.NewLRUCache`
+type Fixture struct {
+	Buckets   int      `json:"buckets"`
+	Capacity  int      `json:"capacity"`
+	Expire    Duration `json:"expire"`
+	LFU       int      `json:"lfu"` // capacity of lfu level per bucket, 0 to disable
+	LFUExpire Duration `json:"lfu_expire"`
+	Entries   []Entry  `json:"entries"`
+}
+
+// Build - create a cache driven by a fake clock, entries are put from the oldest to the newest
+func (f *Fixture) Build() (*cache.Cache, *Clock) {
+	clk := &Clock{}
+	c := cache.NewLRUCache(f.Buckets, f.Capacity, time.Duration(f.Expire)).Clock(clk)
+	if f.LFU > 0 {
+		c.LFU(f.LFU)
+		if f.LFUExpire > 0 {
+			c.LFUExpire(time.Duration(f.LFUExpire))
+		}
+	}
+
+	entries := make([]Entry, len(f.Entries))
+	copy(entries, f.Entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Age > entries[j].Age })
+	var oldest Duration
+	if len(entries) > 0 {
+		oldest = entries[0].Age
+	}
+	for _, e := range entries {
+		clk.ns = int64(oldest - e.Age)
+		c.Put(e.Key, e.Value)
+	}
+	clk.ns = int64(oldest)
+	return c, clk
+}
+
+// FromFile - build a cache from a json fixture file, and the fake clock that drives it
+func FromFile(path string) (*cache.Cache, *Clock, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var f Fixture
+	if err = json.Unmarshal(b, &f); err != nil {
+		return nil, nil, err
+	}
+	c, clk := f.Build()
+	return c, clk, nil
+}
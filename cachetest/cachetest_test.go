@@ -0,0 +1,43 @@
+package cachetest
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_FromFile(t *testing.T) {
+	c, clk, err := FromFile("testdata/fixture.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("uid3"); ok { // evicted, it's the oldest one
+		t.Error("case 1 failed")
+	}
+	if v, ok := c.Get("uid1"); !ok || v.(map[string]interface{})["name"] != "orca" {
+		t.Error("case 2 failed")
+	}
+	if v, ok := c.Get("uid4"); !ok || v != nil {
+		t.Error("case 3 failed")
+	}
+	clk.Add(time.Second + 1)
+	if _, ok := c.Get("uid1"); ok {
+		t.Error("case 4 failed")
+	}
+	if v, ok := c.Get("uid2"); !ok || v != "fresh" {
+		t.Error("case 5 failed")
+	}
+
+	if _, _, err = FromFile("testdata/none.json"); err == nil {
+		t.Error("case 6 failed")
+	}
+}
+
+func Test_Duration(t *testing.T) {
+	var d Duration
+	if err := d.UnmarshalJSON([]byte(`"1m30s"`)); err != nil || time.Duration(d) != 90*time.Second {
+		t.Error("case 1 failed")
+	}
+	if err := d.UnmarshalJSON([]byte(`90`)); err == nil {
+		t.Error("case 2 failed")
+	}
+}
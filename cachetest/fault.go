@@ -0,0 +1,113 @@
+package cachetest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/orca-zhang/cache"
+)
+
+// Faults - misbehaviors injected by `Faulty`, zero value injects nothing
+type Faults struct {
+	Latency   time.Duration // added to each operation
+	Jitter    time.Duration // extra random latency in [0, Jitter)
+	MissRate  float64       // fraction of `Get` that miss even if the item is cached, as if the cache is failing
+	DropRate  float64       // fraction of `Put` that are lost silently
+	EvictRate float64       // fraction of `Put` whose item is evicted right away, as if the cache is under capacity pressure
+}
+
+// FaultStats - count of injected faults
+type FaultStats struct {
+	Misses    uint64
+	Drops     uint64
+	Evictions uint64
+}
+
+// Faulty - decorator of cache.Interface that injects latency, failures and capacity pressure,
+// to test degradation behavior (timeouts, fallbacks) of code using cache without elaborate mocks
+type Faulty struct {
+	c     cache.Interface
+	mu    sync.Mutex
+	f     Faults
+	rnd   *rand.Rand
+	stats FaultStats
+}
+
+// NewFaulty - wrap cache `c` with faults `f`, `seed` makes the injection reproducible
+func NewFaulty(c cache.Interface, f Faults, seed int64) *Faulty {
+	return &Faulty{c: c, f: f, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// SetFaults - change the faults at runtime, e.g. to simulate an outage and the recovery
+func (f *Faulty) SetFaults(faults Faults) {
+	f.mu.Lock()
+	f.f = faults
+	f.mu.Unlock()
+}
+
+// Stats - count of faults injected so far
+func (f *Faulty) Stats() FaultStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+// latency of an operation, lock must be held
+func (f *Faulty) delay() time.Duration {
+	d := f.f.Latency
+	if f.f.Jitter > 0 {
+		d += time.Duration(f.rnd.Int63n(int64(f.f.Jitter)))
+	}
+	return d
+}
+
+// whether a fault of probability `rate` happens, lock must be held
+func (f *Faulty) happen(rate float64) bool {
+	return rate > 0 && f.rnd.Float64() < rate
+}
+
+// Put - implements cache.Interface
+func (f *Faulty) Put(key string, val interface{}) {
+	f.mu.Lock()
+	d, drop := f.delay(), f.happen(f.f.DropRate)
+	evict := !drop && f.happen(f.f.EvictRate)
+	if drop {
+		f.stats.Drops++
+	} else if evict {
+		f.stats.Evictions++
+	}
+	f.mu.Unlock()
+	time.Sleep(d)
+	if drop {
+		return
+	}
+	f.c.Put(key, val)
+	if evict {
+		f.c.Del(key)
+	}
+}
+
+// Get - implements cache.Interface
+func (f *Faulty) Get(key string) (interface{}, bool) {
+	f.mu.Lock()
+	d, miss := f.delay(), f.happen(f.f.MissRate)
+	if miss {
+		f.stats.Misses++
+	}
+	f.mu.Unlock()
+	time.Sleep(d)
+	if miss {
+		return nil, false
+	}
+	return f.c.Get(key)
+}
+
+// Del - implements cache.Interface
+func (f *Faulty) Del(key string) {
+	f.mu.Lock()
+	d := f.delay()
+	f.mu.Unlock()
+	time.Sleep(d)
+	f.c.Del(key)
+}
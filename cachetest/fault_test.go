@@ -0,0 +1,50 @@
+package cachetest
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/orca-zhang/cache"
+)
+
+func Test_Faulty(t *testing.T) {
+	f := NewFaulty(cache.NewLRUCache(1, 1000, 0), Faults{}, 1)
+	f.Put("1", 1)
+	if v, ok := f.Get("1"); !ok || v != 1 || f.Stats() != (FaultStats{}) {
+		t.Error("case 1 failed")
+	}
+	f.Del("1")
+	if _, ok := f.Get("1"); ok {
+		t.Error("case 2 failed")
+	}
+
+	f.SetFaults(Faults{MissRate: 1})
+	f.Put("x", 1)
+	if _, ok := f.Get("x"); ok || f.Stats().Misses != 1 {
+		t.Error("case 3 failed")
+	}
+
+	f.SetFaults(Faults{DropRate: 0.5, EvictRate: 0.5})
+	for i := 0; i < 1000; i++ {
+		f.Put(strconv.Itoa(i), i)
+	}
+	f.SetFaults(Faults{})
+	hits := 0
+	for i := 0; i < 1000; i++ {
+		if _, ok := f.Get(strconv.Itoa(i)); ok {
+			hits++
+		}
+	}
+	s := f.Stats()
+	if s.Drops < 400 || s.Drops > 600 || s.Evictions < 150 || s.Evictions > 350 || hits != 1000-int(s.Drops+s.Evictions) {
+		t.Error("case 4 failed: ", s, hits)
+	}
+
+	f.SetFaults(Faults{Latency: 10 * time.Millisecond, Jitter: time.Millisecond})
+	start := time.Now()
+	f.Get("1")
+	if d := time.Since(start); d < 10*time.Millisecond {
+		t.Error("case 5 failed: ", d)
+	}
+}
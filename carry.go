@@ -0,0 +1,39 @@
+package cache
+
+import "sync/atomic"
+
+// Carryover - cumulative counters and the sketch of `CountDistinct` of a cache, to carry them over a restart
+// (e.g. saved with the snapshot by package persist), so hit ratio history doesn't reset on each deploy
+type Carryover struct {
+	Stats    ShardStats // only the counters are carried, occupancy is not
+	Distinct []uint8    // registers of the sketch of `CountDistinct`, nil if it's not enabled
+}
+
+// Carryover - get the cumulative counters (including those carried over before) and the sketch of `CountDistinct`
+func (c *Cache) Carryover() (co Carryover) {
+	s := c.Stats()
+	co.Stats = ShardStats{Puts: s.Puts, Hits: s.Hits, Misses: s.Misses, Expired: s.Expired, Evictions: s.Evictions,
+		LockWaits: s.LockWaits, LockWait: s.LockWait, Installs: s.Installs, Archived: s.Archived, ArchiveDrops: s.ArchiveDrops}
+	if c.distinct != nil {
+		co.Distinct = make([]uint8, len(c.distinct.regs))
+		for i := range co.Distinct {
+			co.Distinct[i] = uint8(atomic.LoadUint32(&c.distinct.regs[i]))
+		}
+	}
+	return
+}
+
+// AddCarryover - add counters carried over from a former process to the totals of `Stats`, and merge the sketch
+// into `CountDistinct` if it's enabled, the counters are kept apart from buckets, so `ShardStats` reports this process only
+func (c *Cache) AddCarryover(co Carryover) {
+	co.Stats.Len, co.Stats.Cap, co.Stats.LFULen, co.Stats.LFUCap = 0, 0, 0, 0
+	co.Stats.Cost, co.Stats.Budget, co.Stats.LFUCost, co.Stats.LFUBudget = 0, 0, 0, 0
+	c.carryMu.Lock()
+	c.carried.add(&co.Stats)
+	c.carryMu.Unlock()
+	if c.distinct != nil && len(co.Distinct) == len(c.distinct.regs) {
+		for i, r := range co.Distinct {
+			c.distinct.raise(i, uint32(r))
+		}
+	}
+}
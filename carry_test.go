@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Carryover(t *testing.T) {
+	lc := NewLRUCache(2, 10, time.Second).CountDistinct()
+	lc.Put("1", 1)
+	lc.Get("1")
+	lc.Get("2")
+	co := lc.Carryover()
+	if co.Stats.Puts != 1 || co.Stats.Hits != 1 || co.Stats.Misses != 1 || co.Stats.Len != 0 || len(co.Distinct) != 1<<hllP {
+		t.Error("case 1 failed: ", co.Stats)
+	}
+
+	lc2 := NewLRUCache(2, 10, time.Second).CountDistinct()
+	lc2.AddCarryover(co)
+	lc2.Put("3", 3)
+	lc2.Get("3")
+	if s := lc2.Stats(); s.Puts != 2 || s.Hits != 2 || s.Misses != 1 || s.Len != 1 || s.DistinctKeys != 3 {
+		t.Error("case 2 failed: ", s.ShardStats, s.DistinctKeys)
+	}
+	if s := lc2.ShardStats(hashCode("3") & lc2.mask); s.Puts != 1 || s.Hits != 1 {
+		t.Error("case 3 failed: ", s)
+	}
+	if co := lc2.Carryover(); co.Stats.Puts != 2 || co.Stats.Installs[FromPut] != 2 {
+		t.Error("case 4 failed: ", co.Stats)
+	}
+
+	// sketch is skipped if it's not enabled
+	lc3 := NewLRUCache(2, 10, time.Second)
+	for i := 0; i < 10; i++ {
+		lc3.Get(strconv.Itoa(i))
+	}
+	if co := lc3.Carryover(); co.Distinct != nil || co.Stats.Misses != 10 {
+		t.Error("case 5 failed")
+	}
+	lc3.AddCarryover(co)
+	if s := lc3.Stats(); s.DistinctKeys != 0 || s.Misses != 11 {
+		t.Error("case 6 failed: ", s.ShardStats)
+	}
+}
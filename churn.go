@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	sketchDepth = 4
+	sketchWidth = 1 << 12 // power of 2 for masking
+	sketchReset = 16 * sketchWidth
+)
+
+// count-min sketch with atomic counters
+type sketch struct {
+	cnts [sketchDepth][sketchWidth]uint32
+}
+
+// 64-bit fnv-1a, without conversion to []byte
+func hash64(s string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+// increase count of hash `h`, returns the estimated count after increment
+func (s *sketch) add(h uint64) uint32 {
+	est := ^uint32(0)
+	h1, h2 := uint32(h), uint32(h>>32)
+	for i := range s.cnts {
+		if n := atomic.AddUint32(&s.cnts[i][(h1+uint32(i)*h2)&(sketchWidth-1)], 1); n < est {
+			est = n
+		}
+	}
+	return est
+}
+
+// estimated count of hash `h`
+func (s *sketch) estimate(h uint64) uint32 {
+	est := ^uint32(0)
+	h1, h2 := uint32(h), uint32(h>>32)
+	for i := range s.cnts {
+		if n := atomic.LoadUint32(&s.cnts[i][(h1+uint32(i)*h2)&(sketchWidth-1)]); n < est {
+			est = n
+		}
+	}
+	return est
+}
+
+// approximate, increments racing with it may be lost
+func (s *sketch) halve() {
+	for i := range s.cnts {
+		for j := range s.cnts[i] {
+			atomic.StoreUint32(&s.cnts[i][j], atomic.LoadUint32(&s.cnts[i][j])/2)
+		}
+	}
+}
+
+// ChurnStat - estimated recent overwrites and reads of a key
+type ChurnStat struct {
+	Key  string
+	Puts uint32 // estimated recent puts
+	Gets uint32 // estimated recent hits
+}
+
+// min-heap of top churning keys
+type churnHeap struct {
+	items []ChurnStat
+	pos   map[string]int
+}
+
+func (h *churnHeap) Len() int           { return len(h.items) }
+func (h *churnHeap) Less(i, j int) bool { return h.items[i].Puts < h.items[j].Puts }
+func (h *churnHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.pos[h.items[i].Key], h.pos[h.items[j].Key] = i, j
+}
+func (h *churnHeap) Push(x interface{}) {
+	h.pos[x.(ChurnStat).Key] = len(h.items)
+	h.items = append(h.items, x.(ChurnStat))
+}
+func (h *churnHeap) Pop() interface{} {
+	x := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.pos, x.Key)
+	return x
+}
+
+// churn tracks keys that are re-put constantly
+// counts are halved every `sketchReset` puts (aging)
+type churn struct {
+	total uint64 // keep first for 64-bit alignment of atomic ops
+	min   uint32 // puts of the coldest tracked key once full, to skip locking
+	k     int
+	puts  sketch
+	gets  sketch
+	mu    sync.Mutex
+	top   churnHeap
+}
+
+func (c *churn) put(key string) {
+	if atomic.AddUint64(&c.total, 1)%sketchReset == 0 {
+		c.age()
+	}
+	est := c.puts.add(hash64(key))
+	if est <= atomic.LoadUint32(&c.min) {
+		return
+	}
+	c.mu.Lock()
+	if i, ok := c.top.pos[key]; ok {
+		c.top.items[i].Puts = est
+		heap.Fix(&c.top, i)
+	} else if c.top.Len() < c.k {
+		heap.Push(&c.top, ChurnStat{Key: key, Puts: est})
+	} else if est > c.top.items[0].Puts {
+		delete(c.top.pos, c.top.items[0].Key)
+		c.top.items[0] = ChurnStat{Key: key, Puts: est}
+		c.top.pos[key] = 0
+		heap.Fix(&c.top, 0)
+	}
+	if c.top.Len() >= c.k {
+		atomic.StoreUint32(&c.min, c.top.items[0].Puts)
+	}
+	c.mu.Unlock()
+}
+
+func (c *churn) age() {
+	c.mu.Lock()
+	c.puts.halve()
+	c.gets.halve()
+	for i := range c.top.items {
+		c.top.items[i].Puts /= 2 // keeps the heap order
+	}
+	atomic.StoreUint32(&c.min, atomic.LoadUint32(&c.min)/2)
+	c.mu.Unlock()
+}
+
+func (c *churn) get(key string) {
+	c.gets.add(hash64(key))
+}
+
+// Churn - track how often keys are overwritten, so keys re-put constantly (wasting cache space
+// and backend work) can be found with `TopChurningKeys`
+// `k` is count of top keys to keep track of, counts are estimated by count-min sketches that age over time
+func (c *Cache) Churn(k int) *Cache {
+	if k <= 0 {
+		c.churn = nil
+		return c
+	}
+	c.churn = &churn{k: k, top: churnHeap{pos: make(map[string]int, k)}}
+	return c
+}
+
+// TopChurningKeys - get at most `n` keys that are put most frequently, with their estimated puts and gets
+func (c *Cache) TopChurningKeys(n int) []ChurnStat {
+	if c.churn == nil {
+		return nil
+	}
+	c.churn.mu.Lock()
+	res := make([]ChurnStat, len(c.churn.top.items))
+	copy(res, c.churn.top.items)
+	c.churn.mu.Unlock()
+	for i := range res {
+		res[i].Puts = c.churn.puts.estimate(hash64(res[i].Key)) // may have been halved since
+		res[i].Gets = c.churn.gets.estimate(hash64(res[i].Key))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Puts > res[j].Puts })
+	if n < 0 {
+		n = 0
+	}
+	if n < len(res) {
+		res = res[:n]
+	}
+	return res
+}
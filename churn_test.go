@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_sketch(t *testing.T) {
+	var s sketch
+	for i := 0; i < 10; i++ {
+		s.add(hash64("1"))
+	}
+	s.add(hash64("2"))
+	if s.estimate(hash64("1")) < 10 || s.estimate(hash64("2")) < 1 {
+		t.Error("case 1 failed")
+	}
+	s.halve()
+	if s.estimate(hash64("1")) < 5 || s.estimate(hash64("1")) > 6 {
+		t.Error("case 2 failed")
+	}
+}
+
+func Test_TopChurningKeys(t *testing.T) {
+	lc := NewLRUCache(4, 100, time.Second)
+	if lc.TopChurningKeys(3) != nil {
+		t.Error("case 1 failed")
+	}
+
+	lc.Churn(2)
+	for i := 0; i < 50; i++ {
+		lc.Put("hot", i)
+		lc.Put("warm", i)
+		lc.Put("warm", i)
+		lc.Put(strconv.Itoa(i), i)
+	}
+	lc.Get("hot")
+	lc.Get("hot")
+	res := lc.TopChurningKeys(3)
+	if len(res) != 2 || res[0].Key != "warm" || res[0].Puts < 100 || res[1].Key != "hot" || res[1].Puts < 50 {
+		t.Error("case 2 failed: ", res)
+	}
+	if res[1].Gets < 2 || res[0].Gets != 0 {
+		t.Error("case 3 failed: ", res)
+	}
+	if res = lc.TopChurningKeys(1); len(res) != 1 || res[0].Key != "warm" {
+		t.Error("case 4 failed: ", res)
+	}
+	if res = lc.TopChurningKeys(-1); len(res) != 0 {
+		t.Error("case 5 failed: ", res)
+	}
+
+	for i := 0; i < sketchReset; i++ { // aging
+		lc.Put(strconv.Itoa(i), i)
+	}
+	if res = lc.TopChurningKeys(2); len(res) != 2 || res[0].Puts > 100 {
+		t.Error("case 6 failed: ", res)
+	}
+}
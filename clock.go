@@ -0,0 +1,33 @@
+package cache
+
+import "time"
+
+// Clock - source of time used for expiration, ages of items and the decisions depending on them,
+// the core paths need no timers or background goroutines, so it runs anywhere including GOOS=js/wasip1 plugin runtimes,
+// replace it if the host provides its own time, wall clock timestamps (versions of tombstones, `Trace` records)
+// and lock wait stats still read the system time, opt-in features run on real timers or background goroutines
+// (`ExpireAfter`, `Janitor`, `PinnedJanitor`, `Shadow`, `WatchBalance`, `WatchHitRatio`, `Archive` and `OnEvictAsync`),
+// their schedules aren't affected by a replaced Clock
+type Clock interface {
+	Now() int64 // nano timestamp, only the difference between two calls matters
+}
+
+// reference point with monotonic clock reading
+var epoch = time.Now()
+
+// system clock, monotonic since process start, so it has nanosecond granularity on all platforms
+// (wall clock may be coarse, e.g. on windows) and is immune to wall clock jumps
+type sysClock struct{}
+
+func (sysClock) Now() int64 {
+	return int64(time.Since(epoch))
+}
+
+// Clock - replace the time source of the cache, e.g. a host-provided clock in wasm or a fake clock in tests
+func (c *Cache) Clock(clk Clock) *Cache {
+	if clk == nil {
+		clk = sysClock{}
+	}
+	c.clock = clk
+	return c
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// manually advanced clock for tests
+type fakeClock struct {
+	ns int64
+}
+
+func (f *fakeClock) Now() int64 {
+	return atomic.LoadInt64(&f.ns)
+}
+
+func (f *fakeClock) Add(d time.Duration) {
+	atomic.AddInt64(&f.ns, int64(d))
+}
+
+func Test_Clock(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).Clock(clk)
+	lc.Put("1", "1")
+	clk.Add(time.Second)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 1 failed")
+	}
+	clk.Add(1)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 2 failed")
+	}
+
+	lc = NewLRUCache(1, 3, time.Second).Clock(nil)
+	if _, ok := lc.clock.(sysClock); !ok {
+		t.Error("case 3 failed")
+	}
+}
+
+func Test_sysClock(t *testing.T) {
+	var clk sysClock
+	last := clk.Now()
+	for i := 0; i < 1000; i++ {
+		now := clk.Now()
+		if now < last {
+			t.Fatal("case 1 failed")
+		}
+		last = now
+	}
+	// granularity is far finer than a millisecond
+	start := clk.Now()
+	for clk.Now() == start {
+	}
+	if clk.Now()-start >= int64(time.Millisecond) {
+		t.Error("case 2 failed")
+	}
+}
+
+func Test_microsecondExpire(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, 10*time.Microsecond).Clock(clk)
+	lc.Put("1", "1")
+	clk.Add(10 * time.Microsecond)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 1 failed")
+	}
+	clk.Add(time.Nanosecond)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 2 failed")
+	}
+
+	lc = NewLRUCache(1, 3, time.Nanosecond).Clock(clk)
+	lc.Put("1", "1")
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 3 failed")
+	}
+	clk.Add(2 * time.Nanosecond)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 4 failed")
+	}
+
+	// with the system clock
+	lc = NewLRUCache(1, 3, 50*time.Microsecond)
+	lc.Put("1", "1")
+	for start := time.Now(); time.Since(start) < 100*time.Microsecond; {
+	}
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 5 failed")
+	}
+}
@@ -0,0 +1,234 @@
+// Command cachebench runs standardized workloads against the cache (and other caches behind adapters),
+// and writes the results as CSV or JSON, so the numbers are reproducible on your own hardware.
+//
+// Each operation gets a key and puts it on miss. Workloads:
+//
+//	zipf     keys drawn from a zipfian distribution (s=1.01), the typical hot-key traffic
+//	uniform  keys drawn uniformly, every key is equally hot
+//	scan     zipf traffic mixed with 20% of sequential passes over the whole key space
+//	loop     sequential cycle over 1.5x of the capacity, which defeats plain LRU
+//
+// Usage:
+//
+//	cachebench -caches lru,lfu -workloads zipf,scan -goroutines 1,8 -buckets 16 -format json
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/orca-zhang/cache"
+)
+
+// adapters of caches under test, add other libraries here, each one is created with total capacity
+// split over `buckets` (ignored by the ones without buckets)
+var adapters = map[string]func(capacity, buckets int) cache.Interface{
+	"lru": func(capacity, buckets int) cache.Interface {
+		return cache.NewLRUCache(buckets, perBucket(capacity, buckets), 0)
+	},
+	"lfu": func(capacity, buckets int) cache.Interface {
+		return cache.NewLRUCache(buckets, perBucket(capacity*3/4, buckets), 0).LFU(perBucket(capacity/4, buckets))
+	},
+}
+
+// capacity of each bucket, at least 1 so a small capacity over many buckets still caches
+func perBucket(capacity, buckets int) int {
+	if n := capacity / buckets; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// key index generator of a workload, `i` is the sequence of the operation of the goroutine
+type workload func(r *rand.Rand, i, keys, capacity int) int
+
+var workloads = map[string]func(r *rand.Rand, keys int) workload{
+	"zipf": func(r *rand.Rand, keys int) workload {
+		z := rand.NewZipf(r, 1.01, 1, uint64(keys-1))
+		return func(r *rand.Rand, i, keys, capacity int) int { return int(z.Uint64()) }
+	},
+	"uniform": func(r *rand.Rand, keys int) workload {
+		return func(r *rand.Rand, i, keys, capacity int) int { return r.Intn(keys) }
+	},
+	"scan": func(r *rand.Rand, keys int) workload {
+		z := rand.NewZipf(r, 1.01, 1, uint64(keys-1))
+		next := 0
+		return func(r *rand.Rand, i, keys, capacity int) int {
+			if r.Intn(5) == 0 {
+				next = (next + 1) % keys
+				return next
+			}
+			return int(z.Uint64())
+		}
+	},
+	"loop": func(r *rand.Rand, keys int) workload {
+		return func(r *rand.Rand, i, keys, capacity int) int { return i % (capacity + capacity/2) }
+	},
+}
+
+// Result - a run of a workload against a cache
+type Result struct {
+	Cache       string  `json:"cache"`
+	Workload    string  `json:"workload"`
+	Goroutines  int     `json:"goroutines"`
+	Ops         int     `json:"ops"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	HitRatio    float64 `json:"hit_ratio"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+}
+
+type config struct {
+	keys, capacity, buckets, ops int
+	seed                         int64
+}
+
+// run `ops` operations of workload `wl` split over `g` goroutines against a new cache by `adapter`
+func run(adapter func(int, int) cache.Interface, wl func(*rand.Rand, int) workload, g int, cfg config) (r Result) {
+	c := adapter(cfg.capacity, cfg.buckets)
+	keys := make([]string, cfg.keys)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	var hits int64
+	var wg sync.WaitGroup
+	var m0, m1 runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m0)
+	start := time.Now()
+	for n := 0; n < g; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(cfg.seed + int64(n)))
+			next, h := wl(rnd, cfg.keys), int64(0)
+			for i := 0; i < cfg.ops/g; i++ {
+				k := keys[next(rnd, i, cfg.keys, cfg.capacity)%cfg.keys]
+				if _, ok := c.Get(k); ok {
+					h++
+				} else {
+					c.Put(k, k)
+				}
+			}
+			atomic.AddInt64(&hits, h)
+		}(n)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&m1)
+	r.Goroutines, r.Ops = g, cfg.ops/g*g
+	if r.Ops > 0 {
+		r.NsPerOp = float64(elapsed.Nanoseconds()) / float64(r.Ops)
+		r.HitRatio = float64(hits) / float64(r.Ops)
+		r.AllocsPerOp = float64(m1.Mallocs-m0.Mallocs) / float64(r.Ops)
+	}
+	return
+}
+
+func split(s string) (l []string) {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l = append(l, v)
+		}
+	}
+	return
+}
+
+func names(m interface{}) string {
+	var l []string
+	switch m := m.(type) {
+	case map[string]func(capacity, buckets int) cache.Interface:
+		for k := range m {
+			l = append(l, k)
+		}
+	case map[string]func(r *rand.Rand, keys int) workload:
+		for k := range m {
+			l = append(l, k)
+		}
+	}
+	sort.Strings(l)
+	return strings.Join(l, ",")
+}
+
+func write(w io.Writer, format string, results []Result) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"cache", "workload", "goroutines", "ops", "ns_per_op", "hit_ratio", "allocs_per_op"})
+	for _, r := range results {
+		cw.Write([]string{r.Cache, r.Workload, strconv.Itoa(r.Goroutines), strconv.Itoa(r.Ops),
+			strconv.FormatFloat(r.NsPerOp, 'f', 2, 64), strconv.FormatFloat(r.HitRatio, 'f', 4, 64),
+			strconv.FormatFloat(r.AllocsPerOp, 'f', 2, 64)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func main() {
+	var (
+		caches     = flag.String("caches", names(adapters), "caches to run, comma separated")
+		wls        = flag.String("workloads", names(workloads), "workloads to run, comma separated")
+		goroutines = flag.String("goroutines", "1,"+strconv.Itoa(runtime.GOMAXPROCS(0)), "counts of goroutines, comma separated")
+		keys       = flag.Int("keys", 1<<20, "size of the key space")
+		capacity   = flag.Int("capacity", 1<<16, "total capacity of the cache")
+		buckets    = flag.Int("buckets", 16, "count of buckets, the same on any machine so results are comparable")
+		ops        = flag.Int("ops", 1<<22, "operations per run")
+		seed       = flag.Int64("seed", 1, "seed of random workloads")
+		format     = flag.String("format", "csv", "output format, csv or json")
+	)
+	flag.Parse()
+	if *keys <= 1 || *capacity <= 0 || *buckets <= 0 || *ops <= 0 || *format != "csv" && *format != "json" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var results []Result
+	cfg := config{*keys, *capacity, *buckets, *ops, *seed}
+	for _, cn := range split(*caches) {
+		adapter, ok := adapters[cn]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown cache %q, available: %s\n", cn, names(adapters))
+			os.Exit(2)
+		}
+		for _, wn := range split(*wls) {
+			wl, ok := workloads[wn]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "unknown workload %q, available: %s\n", wn, names(workloads))
+				os.Exit(2)
+			}
+			seen := map[int]bool{}
+			for _, gs := range split(*goroutines) {
+				g, err := strconv.Atoi(gs)
+				if err != nil || g <= 0 {
+					fmt.Fprintf(os.Stderr, "bad count of goroutines %q\n", gs)
+					os.Exit(2)
+				}
+				if seen[g] {
+					continue
+				}
+				seen[g] = true
+				r := run(adapter, wl, g, cfg)
+				r.Cache, r.Workload = cn, wn
+				results = append(results, r)
+			}
+		}
+	}
+	if err := write(os.Stdout, *format, results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
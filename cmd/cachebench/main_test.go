@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func Test_run(t *testing.T) {
+	cfg := config{keys: 1000, capacity: 100, buckets: 4, ops: 10000, seed: 1}
+	zipf := run(adapters["lru"], workloads["zipf"], 2, cfg)
+	uniform := run(adapters["lru"], workloads["uniform"], 2, cfg)
+	if zipf.Ops != 10000 || zipf.Goroutines != 2 || zipf.NsPerOp <= 0 {
+		t.Error("case 1 failed: ", zipf)
+	}
+	if zipf.HitRatio <= uniform.HitRatio || uniform.HitRatio > 0.2 {
+		t.Error("case 2 failed: ", zipf.HitRatio, uniform.HitRatio)
+	}
+	if loop := run(adapters["lru"], workloads["loop"], 1, cfg); loop.HitRatio != 0 {
+		t.Error("case 3 failed: ", loop.HitRatio)
+	}
+	for name, wl := range workloads {
+		if r := run(adapters["lfu"], wl, 1, cfg); r.Ops != 10000 {
+			t.Error("case 4 failed: ", name)
+		}
+	}
+}
+
+func Test_write(t *testing.T) {
+	results := []Result{{Cache: "lru", Workload: "zipf", Goroutines: 1, Ops: 10, NsPerOp: 1.5, HitRatio: 0.5}}
+	var buf bytes.Buffer
+	write(&buf, "csv", results)
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || lines[1] != "lru,zipf,1,10,1.50,0.5000,0.00" {
+		t.Error("case 1 failed: ", lines)
+	}
+	buf.Reset()
+	write(&buf, "json", results)
+	var got []Result
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || len(got) != 1 || got[0] != results[0] {
+		t.Error("case 2 failed: ", err, got)
+	}
+	if split(" a, ,b,") == nil || len(split(" a, ,b,")) != 2 || names(adapters) != "lfu,lru" {
+		t.Error("case 3 failed")
+	}
+}
+
+func Test_perBucket(t *testing.T) {
+	if perBucket(100, 4) != 25 || perBucket(10, 64) != 1 {
+		t.Error("case 1 failed")
+	}
+	if c := adapters["lfu"](10, 64); c == nil {
+		t.Error("case 2 failed")
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import "time"
+
+// internal sub function that find the live item and its level without refreshing it, lock of the bucket must be held
+func (c *Cache) peek(key string, idx int) (*wrapper, int) {
+	now := c.clock.Now()
+	for level, inst := range c.insts[idx] {
+		if inst == nil {
+			continue
+		}
+		if e, ok := inst.hmap[key]; ok && !c.expired(e.v, now, level) {
+			return e.v, level
+		}
+	}
+	return nil, 0
+}
+
+// get the stored item of key even if it's expired and the level of it, lock of the bucket must be held
+func (c *Cache) stored(key string, idx int) (*wrapper, int) {
+	for level, inst := range c.insts[idx] {
+		if inst == nil {
+			continue
+		}
+		if e, ok := inst.hmap[key]; ok {
+			return e.v, level
+		}
+	}
+	return nil, 0
+}
+
+// internal sub function that put a item with version `ver` if `cond` reports true for the live item (nil if absent)
+func (c *Cache) putIf(key string, val interface{}, ver int64, cond func(w *wrapper) bool) bool {
+	if c.dryRun {
+		val = nil // keys only
+	} else if c.pipeline != nil {
+		var ok bool
+		if val, ok = c.pipeline.encode(val); !ok {
+			return false
+		}
+	}
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	if c.freezes[idx] != nil {
+		c.thawed(idx) // the condition can't be decided before deferred writes are applied
+	}
+	if cur, _ := c.peek(key, idx); !cond(cur) || c.tombs != nil && c.buried(key, idx, ver) {
+		c.locks[idx].Unlock()
+		return false
+	}
+	if c.interns != nil {
+		val = c.intern(idx, val)
+	}
+	w := c.alloc(idx, val, c.now())
+	w.ver = ver
+	ok := c.insert(key, idx, 0, w)
+	if c.sweep > 0 {
+		c.step(idx, c.sweep)
+	}
+	c.locks[idx].Unlock()
+	if c.churn != nil {
+		c.churn.put(key)
+	}
+	return ok
+}
+
+// PutIfAbsent - put a item into cache only if the key is absent (or expired), returns whether it's put
+func (c *Cache) PutIfAbsent(key string, val interface{}) bool {
+	return c.putIf(key, val, 0, func(w *wrapper) bool { return w == nil })
+}
+
+// PutIfNewer - put a item into cache only if the present one is older than `ts` (or absent, or expired),
+// returns whether it's put, so an older event from other replicas never overwrites a newer state
+// with `Tombstone`, it's also put only if `ts` is after the deletion of key
+// `ts` is kept with the item as its version, items put by `Put` have no version and are older than any `ts`
+func (c *Cache) PutIfNewer(key string, val interface{}, ts time.Time) bool {
+	ver := ts.UnixNano()
+	return c.putIf(key, val, ver, func(w *wrapper) bool { return w == nil || w.ver < ver })
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_PutIfAbsent(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).LFU(3).Clock(clk)
+	if !lc.PutIfAbsent("1", "1") {
+		t.Error("case 1 failed")
+	}
+	if lc.PutIfAbsent("1", "2") {
+		t.Error("case 2 failed")
+	}
+	lc.Get("1") // l0 -> l1
+	if lc.PutIfAbsent("1", "2") {
+		t.Error("case 3 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != "1" {
+		t.Error("case 4 failed")
+	}
+	clk.Add(2 * time.Second)
+	if !lc.PutIfAbsent("1", "3") {
+		t.Error("case 5 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != "3" {
+		t.Error("case 6 failed")
+	}
+}
+
+func Test_PutIfNewer(t *testing.T) {
+	lc := NewLRUCache(1, 3, time.Second)
+	t0 := time.Now()
+	lc.Put("1", "0")
+	if !lc.PutIfNewer("1", "1", t0) {
+		t.Error("case 1 failed")
+	}
+	if lc.PutIfNewer("1", "2", t0) || lc.PutIfNewer("1", "2", t0.Add(-time.Millisecond)) {
+		t.Error("case 2 failed")
+	}
+	if !lc.PutIfNewer("1", "3", t0.Add(time.Millisecond)) {
+		t.Error("case 3 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != "3" {
+		t.Error("case 4 failed")
+	}
+	if !lc.PutIfNewer("2", "1", t0) {
+		t.Error("case 5 failed")
+	}
+}
@@ -0,0 +1,47 @@
+// Package debughttp provides http handlers to inspect and manage the caches in the process-wide registry
+// (see `cache.Register`), apart from the core package so programs not serving them don't link net/http.
+package debughttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/orca-zhang/cache"
+)
+
+// Handler - http handler that serves `cache.AllStats` as json on GET,
+// and `Clear` the cache named by form value "purge" on POST ("*" for all)
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			total, each := cache.AllStats()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(struct {
+				Total  cache.ShardStats
+				Caches map[string]cache.ShardStats
+			}{total, each})
+		case http.MethodPost:
+			name := r.FormValue("purge")
+			if name == "*" {
+				cache.PurgeAll()
+			} else if c, ok := cache.Lookup(name); ok {
+				c.Clear()
+			} else {
+				http.Error(w, "cache: unknown cache "+name, http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// Metrics - http handler that serves `cache.WriteOpenMetrics`, e.g. to be scraped by Prometheus
+func Metrics() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		cache.WriteOpenMetrics(w)
+	})
+}
@@ -0,0 +1,64 @@
+package debughttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/orca-zhang/cache"
+)
+
+func Test_Handler(t *testing.T) {
+	a, b := cache.NewLRUCache(1, 10, 0), cache.NewLRUCache(2, 10, 0)
+	cache.Register("a", a)
+	cache.Register("b", b)
+	defer cache.Unregister("a")
+	defer cache.Unregister("b")
+	a.Put("1", 1)
+	b.Put("1", 1)
+	b.Put("2", 2)
+	b.Get("1")
+
+	h := Handler()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var resp struct {
+		Total  cache.ShardStats
+		Caches map[string]cache.ShardStats
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Total.Len != 3 || resp.Caches["b"].Hits != 1 {
+		t.Error("case 1 failed: ", err, resp)
+	}
+
+	post := func(form string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if post("purge=a") != http.StatusNoContent || a.Len() != 0 || b.Len() != 2 {
+		t.Error("case 2 failed")
+	}
+	if post("purge=none") != http.StatusNotFound {
+		t.Error("case 3 failed")
+	}
+	if post("purge=*") != http.StatusNoContent || b.Len() != 0 {
+		t.Error("case 4 failed")
+	}
+}
+
+func Test_Metrics(t *testing.T) {
+	a := cache.NewLRUCache(1, 10, 0)
+	cache.Register("a", a)
+	defer cache.Unregister("a")
+	a.Put("1", 1)
+
+	rec := httptest.NewRecorder()
+	Metrics().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/openmetrics-text") || !strings.Contains(rec.Body.String(), "cache_global_items 1\n") {
+		t.Error("case 1 failed")
+	}
+}
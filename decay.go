@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"math"
+	"time"
+)
+
+// decay keeps the aging policy of lfu frequency counters
+type decay struct {
+	halfLife  float64 // in nanoseconds
+	threshold float64
+}
+
+// record an access at `now`, returns the decayed frequency including this access
+func (d *decay) hit(w *wrapper, now int64) float64 {
+	w.freq = w.freq*math.Exp2(-float64(now-w.fts)/d.halfLife) + 1
+	w.fts = now
+	return w.freq
+}
+
+// Decay - replace the "visited twice then promote" rule of lfu-2 with exponentially decayed frequency counters
+// `halfLife` is the time after which an access counts half (the `Put` counts as the first access)
+// `threshold` is the decayed frequency that an item must reach to move to (or stay at) upper-level-cache,
+// promoted items whose popularity faded below it are demoted on their next hit
+// threshold 2 with a long `halfLife` behaves like lfu-2, it works only with `LFU`
+func (c *Cache) Decay(halfLife time.Duration, threshold float64) *Cache {
+	if halfLife <= 0 {
+		c.decay = nil
+		return c
+	}
+	c.decay = &decay{float64(halfLife), threshold}
+	return c
+}
+
+// internal sub function that get item in lfu mode with decayed frequency
+func (c *Cache) getDecay(key string, idx int) (*wrapper, bool) {
+	now := c.clock.Now()
+	if w, b := c.insts[idx][0].get(key); b {
+		if c.expired(w, now, 0) {
+			c.locks[idx].cnts.expired++
+			return w, false
+		}
+		if c.decay.hit(w, now) >= c.decay.threshold && c.promotable(idx, w) {
+			// hot enough, move to level-1
+			c.insts[idx][0].del(key)
+			c.set(key, idx, 1, w)
+			if c.tracer != nil {
+				c.tracer.record("promote", key, 0, nil)
+			}
+		}
+		return w, true
+	}
+	if w, b := c.insts[idx][1].get(key); b {
+		if c.expired(w, now, 1) {
+			c.locks[idx].cnts.expired++
+			if c.lfuExpired != KeepExpired {
+				c.expiredLFU(key, idx, w)
+			}
+			return w, false
+		}
+		if c.decay.hit(w, now) < c.decay.threshold {
+			// popularity faded, move back to level-0
+			c.insts[idx][1].del(key)
+			c.set(key, idx, 0, w)
+			if c.tracer != nil {
+				c.tracer.record("demote", key, 0, nil)
+			}
+		}
+		return w, true
+	}
+	return nil, false
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Decay(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Hour).LFU(3).Decay(time.Minute, 2).Clock(clk)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Get("1") // freq 2, l0 -> l1
+	if lc.insts[0][1].length() != 1 {
+		t.Error("case 1 failed")
+	}
+
+	clk.Add(time.Minute)
+	lc.Get("2") // freq 1.5, stays in l0
+	if lc.insts[0][0].length() != 1 || lc.insts[0][1].length() != 1 {
+		t.Error("case 2 failed")
+	}
+	lc.Get("2") // freq 2.5, l0 -> l1
+	if lc.insts[0][0].length() != 0 || lc.insts[0][1].length() != 2 {
+		t.Error("case 3 failed")
+	}
+
+	clk.Add(3 * time.Minute)
+	if v, ok := lc.Get("1"); !ok || v != "1" { // freq 1.25, l1 -> l0
+		t.Error("case 4 failed")
+	}
+	if lc.insts[0][0].length() != 1 || lc.insts[0][1].length() != 1 {
+		t.Error("case 5 failed")
+	}
+
+	lc.Decay(0, 0)
+	lc.Get("1") // back to lfu-2
+	if lc.insts[0][0].length() != 0 || lc.insts[0][1].length() != 2 {
+		t.Error("case 6 failed")
+	}
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_DistinctKeys(t *testing.T) {
+	lc := NewLRUCache(4, 100, time.Second)
+	lc.Get("1")
+	if lc.DistinctKeys() != 0 {
+		t.Error("case 1 failed")
+	}
+
+	lc.CountDistinct()
+	for i := 0; i < 3; i++ {
+		lc.Get("1")
+		lc.Get("2")
+	}
+	if lc.DistinctKeys() != 2 {
+		t.Error("case 2 failed", lc.DistinctKeys())
+	}
+
+	for _, n := range []int{1000, 100000} {
+		lc.CountDistinct()
+		for i := 0; i < n; i++ {
+			lc.Get(strconv.Itoa(i))
+			lc.Get(strconv.Itoa(i))
+		}
+		if est := float64(lc.DistinctKeys()); est < float64(n)*0.97 || est > float64(n)*1.03 {
+			t.Error("case 3 failed", n, est)
+		}
+	}
+}
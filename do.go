@@ -0,0 +1,35 @@
+package cache
+
+import "time"
+
+// result of `Do` stored as the value of its idempotency key
+type doResult struct {
+	v   interface{}
+	err error
+}
+
+// DoErrors - make `Do` store failed results as well, so replays get the same error instead of calling `fn` again
+func (c *Cache) DoErrors() *Cache {
+	c.doErrors = true
+	return c
+}
+
+// Do - run `fn` once for an idempotency key of api requests and store its result for `ttl` (`0` for never expires),
+// replays of the key get the stored result with `replayed` of true, concurrent ones wait for the running `fn`,
+// failed results are not stored (so the request can be retried) unless `DoErrors` is set,
+// results are stored as values of internal type, use a dedicated cache (or prefix of keys) without `Pipeline`
+func (c *Cache) Do(key string, ttl time.Duration, fn func() (interface{}, error)) (v interface{}, replayed bool, err error) {
+	ran := false
+	res, err := c.GetOrLoadWithTTL(key, ttl, func(string) (interface{}, error) {
+		ran = true
+		v, err := fn()
+		if err != nil && !c.doErrors {
+			return nil, err
+		}
+		return &doResult{v, err}, nil
+	})
+	if r, ok := res.(*doResult); ok {
+		return r.v, !ran, r.err
+	}
+	return nil, !ran, err
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Do(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 10, 0).Clock(clk)
+	var calls int32
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return "resp", nil
+	}
+	if v, replayed, err := lc.Do("req1", time.Minute, fn); v != "resp" || replayed || err != nil {
+		t.Error("case 1 failed")
+	}
+	if v, replayed, err := lc.Do("req1", time.Minute, fn); v != "resp" || !replayed || err != nil || calls != 1 {
+		t.Error("case 2 failed")
+	}
+	clk.Add(2 * time.Minute)
+	if _, replayed, _ := lc.Do("req1", time.Minute, fn); replayed || calls != 2 {
+		t.Error("case 3 failed")
+	}
+
+	// concurrent replays wait for the running one
+	var wg sync.WaitGroup
+	var runs int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, replayed, _ := lc.Do("req2", 0, fn); v != "resp" {
+				t.Error("case 4 failed")
+			} else if !replayed {
+				atomic.AddInt32(&runs, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if runs != 1 || calls != 3 {
+		t.Error("case 5 failed", runs, calls)
+	}
+
+	// failed ones are retried
+	failed := errors.New("failed")
+	fail := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, failed
+	}
+	lc.Do("req3", 0, fail)
+	if _, replayed, err := lc.Do("req3", 0, fail); replayed || err != failed || calls != 5 {
+		t.Error("case 6 failed")
+	}
+
+	// unless errors are stored
+	lc.DoErrors()
+	lc.Do("req4", 0, fail)
+	if _, replayed, err := lc.Do("req4", 0, fail); !replayed || err != failed || calls != 6 {
+		t.Error("case 7 failed")
+	}
+}
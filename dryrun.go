@@ -0,0 +1,33 @@
+package cache
+
+// DryRunStats - what would have happened if the cache were enabled
+type DryRunStats struct {
+	Puts      uint64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Len       int // count of keys that would be cached now, to estimate memory needs
+}
+
+// DryRun - store keys only and never hit, while recording what would have been put/hit/evicted,
+// so memory needs and hit ratio of a new code path can be predicted in staging before enabling the cache
+func (c *Cache) DryRun() *Cache {
+	c.dryRun = true
+	return c
+}
+
+// DryRunStats - get what would have happened, summed over buckets
+func (c *Cache) DryRunStats() (s DryRunStats) {
+	for idx := range c.insts {
+		c.locks[idx].Lock()
+		cnt := c.locks[idx].cnts
+		s.Puts, s.Hits, s.Misses, s.Evictions = s.Puts+cnt.puts, s.Hits+cnt.hits, s.Misses+cnt.misses, s.Evictions+cnt.evictions
+		for _, inst := range c.insts[idx] {
+			if inst != nil {
+				s.Len += inst.length()
+			}
+		}
+		c.locks[idx].Unlock()
+	}
+	return
+}
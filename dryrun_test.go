@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_DryRun(t *testing.T) {
+	lc := NewLRUCache(1, 2, time.Second).DryRun()
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	if v, ok := lc.Get("1"); ok || v != nil {
+		t.Error("case 1 failed")
+	}
+	lc.Get("3")
+	lc.Put("3", "3") // evicts "2"
+	lc.PutIfAbsent("4", "4")
+	s := lc.DryRunStats()
+	if s.Puts != 4 || s.Hits != 1 || s.Misses != 1 || s.Evictions != 2 || s.Len != 2 {
+		t.Error("case 1 failed: ", s)
+	}
+	if v, _ := lc.insts[0][0].get("4"); v.v != nil {
+		t.Error("case 2 failed")
+	}
+}
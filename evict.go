@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// max count of evictions waiting for each worker of `OnEvictAsync` by default
+const evictQueue = 1024
+
+// EvictReason - why a item leaves the cache
+type EvictReason int
+
+const (
+	Evicted  EvictReason = iota // evicted by capacity
+	Expired                     // removed after expiration (by `Sweep`, `Janitor`, `Get`, or evicted by capacity)
+	Deleted                     // deleted by `Del` (or `ReplaceAll` without the key)
+	Replaced                    // replaced by a new value, even if it's expired
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case Evicted:
+		return "evicted"
+	case Expired:
+		return "expired"
+	case Deleted:
+		return "deleted"
+	case Replaced:
+		return "replaced"
+	}
+	return "unknown"
+}
+
+// OnEvict - set a callback that is called when a item leaves the cache, e.g. to release pooled buffers or file handles
+// `val` is as stored (i.e. encoded by `Pipeline` if any), calls for the same key are in order
+// expired items are only noticed lazily unless `Sweep` or `Janitor` is enabled
+// it's called with the lock of the bucket held, it must be fast and must not call back into the cache
+func (c *Cache) OnEvict(fn func(key string, val interface{}, reason EvictReason)) *Cache {
+	c.onEvict = fn
+	return c
+}
+
+type eviction struct {
+	key    string
+	val    interface{}
+	reason EvictReason
+}
+
+// workers of `OnEvictAsync`
+type evictQ struct {
+	chs   []chan eviction
+	block bool
+	wg    sync.WaitGroup
+}
+
+// OnEvictAsync - deliver evictions (as `OnEvict` does) to `fn` in `workers` background goroutines, so `fn` may be slow,
+// evictions of the same key always go to the same worker, so they're delivered in order (e.g. v1 is evicted before v2),
+// at most `queue` (1024 if <= 0) evictions wait for each worker, then further ones are dropped and counted in `EvictDrops` of stats,
+// or if `block`, evictions wait for room with the lock of the bucket held, so writers of the bucket slow down to the pace of `fn`,
+// then `fn` must not write into the cache, `Close` stops them after the waiting evictions are delivered,
+// it's a no-op if the workers are already running
+func (c *Cache) OnEvictAsync(fn func(key string, val interface{}, reason EvictReason), workers, queue int, block bool) *Cache {
+	if c.evictQ != nil {
+		return c
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	if queue <= 0 {
+		queue = evictQueue
+	}
+	q := &evictQ{chs: make([]chan eviction, workers), block: block}
+	for i := range q.chs {
+		q.chs[i] = make(chan eviction, queue)
+		q.wg.Add(1)
+		go func(ch chan eviction) {
+			defer q.wg.Done()
+			for e := range ch {
+				fn(e.key, e.val, e.reason)
+			}
+		}(q.chs[i])
+	}
+	c.evictQ = q
+	return c
+}
+
+// index of the worker of key, `hashCode` is negative for half of keys on 32-bit platforms
+func (q *evictQ) worker(key string) int {
+	return int(uint32(hashCode(key)) % uint32(len(q.chs)))
+}
+
+// hand the eviction to the worker of key, lock of the bucket must be held
+func (c *Cache) handOver(key string, val interface{}, reason EvictReason) {
+	ch, e := c.evictQ.chs[c.evictQ.worker(key)], eviction{key, val, reason}
+	if c.evictQ.block {
+		ch <- e
+		return
+	}
+	select {
+	case ch <- e:
+	default:
+		atomic.AddUint64(&c.evictDrops, 1)
+	}
+}
+
+// stop the workers of `OnEvictAsync` after the waiting evictions are delivered,
+// all buckets are locked at once so no eviction is being handed to them (blocked ones are unblocked as they keep draining)
+func (c *Cache) stopEvictQ() {
+	q := c.evictQ
+	for idx := range c.insts {
+		c.lock(idx)
+	}
+	c.evictQ = nil
+	for idx := range c.insts {
+		c.locks[idx].Unlock()
+	}
+	for _, ch := range q.chs {
+		close(ch)
+	}
+	q.wg.Wait()
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_OnEvict(t *testing.T) {
+	var got []string
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 2, time.Second).LFU(1).Clock(clk).OnEvict(func(key string, val interface{}, reason EvictReason) {
+		got = append(got, fmt.Sprint(key, "=", val, " ", reason))
+	})
+	check := func(c string, want ...string) {
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Error("case "+c+" failed", got)
+		}
+		got = nil
+	}
+	lc.Put("1", "1")
+	lc.Put("1", "2")
+	check("1", "1=1 replaced")
+	lc.Put("2", "2")
+	lc.Put("3", "3")
+	check("2", "1=2 evicted")
+	lc.Get("2") // l0 -> l1
+	lc.Put("4", "4")
+	lc.Get("4") // l0 -> l1
+	check("3", "2=2 evicted")
+	lc.Del("3")
+	lc.Del("3")
+	check("4", "3=3 deleted")
+	lc.Put("5", "5")
+	clk.Add(2 * time.Second)
+	lc.Get("5") // expired in l0
+	check("5", "5=5 expired")
+	lc.Put("4", "6")
+	lc.Put("4", "7")
+	check("6", "4=6 replaced")
+	lc.ReplaceAll(map[string]interface{}{"4": "8"})
+	check("7", "4=7 replaced", "4=4 replaced")
+	lc.ReplaceAll(nil)
+	check("8", "4=8 deleted")
+	if EvictReason(-1).String() != "unknown" {
+		t.Error("case 9 failed")
+	}
+}
+
+func Test_OnEvictAsync(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got = map[string][]interface{}{}
+	)
+	lc := NewLRUCache(4, 100, 0)
+	lc.OnEvictAsync(func(key string, val interface{}, reason EvictReason) {
+		lc.Get(key) // may call back into the cache
+		mu.Lock()
+		got[key] = append(got[key], val)
+		mu.Unlock()
+	}, 4, 0, true)
+	for i := 0; i < 100; i++ {
+		for _, k := range []string{"1", "2", "3"} {
+			lc.Put(k, i)
+		}
+	}
+	lc.Close()
+	for _, k := range []string{"1", "2", "3"} {
+		if len(got[k]) != 99 {
+			t.Error("case 1 failed: ", k, len(got[k]))
+		}
+		for i, v := range got[k] {
+			if v != i {
+				t.Error("case 2 failed: ", k, got[k])
+				break
+			}
+		}
+	}
+	lc.Put("1", "1") // no longer delivered
+	if len(got["1"]) != 99 {
+		t.Error("case 3 failed")
+	}
+
+	// dropped when the queue is full
+	release := make(chan struct{})
+	lc = NewLRUCache(1, 100, 0).OnEvictAsync(func(key string, val interface{}, reason EvictReason) {
+		<-release
+	}, 1, 1, false)
+	for i := 0; i < 5; i++ {
+		lc.Put("1", i)
+	}
+	if s := lc.Stats(); s.EvictDrops < 2 || s.EvictDrops > 3 {
+		t.Error("case 4 failed: ", s.EvictDrops)
+	}
+	close(release)
+	lc.Close()
+
+	// crc32 of "1" has the top bit set, so `hashCode` is negative on 32-bit platforms
+	if crc32.ChecksumIEEE([]byte("1")) < 1<<31 {
+		t.Error("case 5 failed")
+	}
+	q := &evictQ{chs: make([]chan eviction, 3)}
+	if i := q.worker("1"); i != int(crc32.ChecksumIEEE([]byte("1"))%3) {
+		t.Error("case 6 failed: ", i)
+	}
+}
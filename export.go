@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Record - a live item with the metadata to put it back by `Import`, got by `Export`
+type Record struct {
+	Key   string
+	Value interface{}
+	Level int           // 0 for normal lru, 1 for upper-level-cache of lfu
+	Age   time.Duration // since it was put
+	Seen  time.Duration // since the last access counted in `Freq`
+	TTL   time.Duration // remaining if it has its own deadline, -1 for never, 0 to follow expiration of the cache
+	Freq  float64
+	Cost  int64
+	enc   *atomic.Value // bytes of the value kept alongside the item, see `KeepEncoded`
+}
+
+// KeepEncoded - keep the bytes of values encoded by `Record.Encode` alongside the items, so writers of snapshots
+// (or a remote tier) on top of `Export` encode each value once instead of on every write, values are still stored
+// as they are by `Put` and encoded only when they're written, the bytes are kept until the value is replaced
+// (by `Put` or `Update`) or the item leaves, they're not counted in the cost of items
+func (c *Cache) KeepEncoded() *Cache {
+	c.keepEnc = true
+	return c
+}
+
+// Encode - encode the value by `enc`, or get the bytes encoded before if they're kept by `KeepEncoded`,
+// the bytes must not be modified
+func (r Record) Encode(enc func(v interface{}) ([]byte, error)) ([]byte, error) {
+	if r.enc != nil {
+		if b, ok := r.enc.Load().([]byte); ok {
+			return b, nil
+		}
+	}
+	b, err := enc(r.Value)
+	if err == nil && r.enc != nil {
+		r.enc.Store(b)
+	}
+	return b, err
+}
+
+// Export - call f sequentially for each live item with the metadata to restore it, until f returns false,
+// upper-level-cache first, then from least to most recently used per bucket, so that importing them in order restores the ordering,
+// buckets are locked one by one, so it's not a point-in-time view unless all buckets are frozen by `FreezeShard` before,
+// see package persist for snapshots on disk
+func (c *Cache) Export(f func(r Record) bool) {
+	var recs []Record
+	for idx := range c.insts {
+		recs = c.records(idx, recs[:0])
+		for i := range recs {
+			if c.pipeline != nil && !c.dryRun {
+				var ok bool
+				if recs[i].Value, ok = c.pipeline.decode(recs[i].Value); !ok {
+					continue
+				}
+			}
+			if !f(recs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// append records of live items of bucket `idx` to `recs`
+func (c *Cache) records(idx int, recs []Record) []Record {
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	now := c.clock.Now()
+	for level := len(c.insts[idx]) - 1; level >= 0; level-- {
+		inst := c.insts[idx][level]
+		if inst == nil {
+			continue
+		}
+		for e := inst.tail; e != nil; e = e.p {
+			w := e.v
+			if c.expired(w, now, level) {
+				continue
+			}
+			if level == 1 {
+				if n, dup := c.insts[idx][0].hmap[e.k]; dup && !c.expired(n.v, now, 0) {
+					continue // the newer one in level-0
+				}
+			}
+			r := Record{Key: e.k, Value: w.v, Level: level, Freq: w.freq, Cost: w.cost}
+			if c.keepEnc {
+				if w.enc == nil {
+					w.enc = new(atomic.Value)
+				}
+				r.enc = w.enc
+			}
+			if w.ts != 0 {
+				r.Age = time.Duration(now - w.ts)
+			}
+			if w.fts != 0 {
+				r.Seen = time.Duration(now - w.fts)
+			}
+			if w.exp == never {
+				r.TTL = -1
+			} else if w.exp != 0 {
+				r.TTL = time.Duration(w.exp - now)
+			}
+			recs = append(recs, r)
+		}
+	}
+	return recs
+}
+
+// Import - put an item got by `Export` into cache with its metadata, aged by `elapsed` (e.g. downtime of a restart),
+// keys are re-hashed so the count of buckets may differ, items of upper-level-cache stay there if `LFU` is enabled,
+// returns false if it's skipped as expired (by its own deadline or expiration of this cache) or rejected (see `NewLRUCacheWithBudget`)
+func (c *Cache) Import(r Record, elapsed time.Duration) bool {
+	v := r.Value
+	if c.dryRun {
+		v = nil
+	} else if c.pipeline != nil {
+		var ok bool
+		if v, ok = c.pipeline.encode(v); !ok {
+			return false
+		}
+	}
+	level := r.Level
+	if level != 1 || c.insts[0][1] == nil {
+		level = 0
+	}
+	idx := hashCode(r.Key) & c.mask
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	if c.freezes[idx] != nil {
+		c.thawed(idx)
+	}
+	if c.tombs != nil && c.buried(r.Key, idx, 0) {
+		return false
+	}
+	if c.interns != nil {
+		v = c.intern(idx, v)
+	}
+	t := wrapper{freq: r.Freq, cost: r.Cost}
+	if now := c.now(); now != 0 { // timestamps are recorded
+		t.ts, t.fts = now-int64(r.Age+elapsed), now-int64(r.Seen+elapsed)
+	}
+	switch {
+	case r.TTL < 0:
+		t.exp = never
+	case r.TTL > elapsed:
+		t.exp = after(c.clock.Now(), int64(r.TTL-elapsed))
+	case r.TTL > 0:
+		return false // expired while exported
+	}
+	if c.expired(&t, c.clock.Now(), level) {
+		return false
+	}
+	w := c.alloc(idx, v, t.ts)
+	w.fts, w.freq, w.exp, w.cost, w.src = t.fts, t.freq, t.exp, t.cost, FromSnapshot
+	return c.insert(r.Key, idx, level, w)
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_ExportImport(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(4, 100, time.Second).LFU(10).Clock(clk)
+	clk.Add(time.Second) // timestamps are recorded from non-zero
+	lc.Put("1", 1)
+	lc.Get("1") // l0 -> l1
+	lc.PutWithTTL("2", "2", 0)
+	lc.PutWithTTL("3", "3", 500*time.Millisecond)
+	clk.Add(100 * time.Millisecond)
+
+	var (
+		recs []Record
+		rec  Record
+	)
+	lc.Export(func(r Record) bool {
+		recs = append(recs, r)
+		if r.Key == "1" {
+			rec = r
+		}
+		return true
+	})
+	if len(recs) != 3 {
+		t.Error("case 1 failed: ", recs)
+	}
+	n := 0
+	lc.Export(func(r Record) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Error("case 2 failed")
+	}
+
+	clk2 := &fakeClock{}
+	clk2.Add(time.Hour)
+	lc2 := NewLRUCache(8, 100, time.Second).LFU(10).Clock(clk2)
+	for _, r := range recs {
+		if !lc2.Import(r, 0) {
+			t.Error("case 3 failed: ", r)
+		}
+	}
+	views := map[string]EntryView{}
+	lc2.Range(func(e EntryView) bool {
+		views[e.Key] = e
+		return true
+	})
+	if e := views["1"]; e.Value != 1 || e.Level != 1 || e.Age != 100*time.Millisecond || e.TTL != 900*time.Millisecond || e.Source != FromSnapshot {
+		t.Error("case 4 failed: ", e)
+	}
+	if e := views["2"]; e.Value != "2" || e.TTL != -1 {
+		t.Error("case 5 failed: ", e)
+	}
+	if e := views["3"]; e.Value != "3" || e.TTL != 400*time.Millisecond {
+		t.Error("case 6 failed: ", e)
+	}
+
+	// aged by `elapsed`
+	lc2 = NewLRUCache(8, 100, time.Second).LFU(10).Clock(clk2)
+	for _, r := range recs {
+		lc2.Import(r, 450*time.Millisecond)
+	}
+	if _, ok := lc2.Get("3"); ok || lc2.Len() != 2 {
+		t.Error("case 7 failed")
+	}
+	clk2.Add(500 * time.Millisecond)
+	if _, ok := lc2.Get("1"); ok { // 550ms old when imported, 1s of expiration
+		t.Error("case 8 failed")
+	}
+
+	// expired by expiration of the new cache
+	lc3 := NewLRUCache(1, 100, 50*time.Millisecond)
+	if lc3.Import(rec, 0) || !lc3.Import(Record{Key: "4", Value: "4", TTL: -1}, 0) {
+		t.Error("case 9 failed")
+	}
+
+	// the most recently used ones are kept
+	lc = NewLRUCache(1, 100, 0)
+	for i := 0; i < 10; i++ {
+		lc.Put(strconv.Itoa(i), i)
+	}
+	lc.Get("0")
+	lc2 = NewLRUCache(1, 3, 0)
+	lc.Export(func(r Record) bool {
+		lc2.Import(r, 0)
+		return true
+	})
+	for _, k := range []string{"0", "8", "9"} {
+		if _, ok := lc2.Get(k); !ok {
+			t.Error("case 10 failed: ", k)
+		}
+	}
+
+	// the expiration saturates instead of overflowing
+	lc = NewLRUCache(1, 100, 0).Clock(clk2)
+	if !lc.Import(Record{Key: "5", Value: "5", TTL: math.MaxInt64}, 0) {
+		t.Error("case 11 failed")
+	}
+	if _, ok := lc.Get("5"); !ok {
+		t.Error("case 12 failed")
+	}
+}
+
+func Test_KeepEncoded(t *testing.T) {
+	encodes := 0
+	enc := func(v interface{}) ([]byte, error) {
+		encodes++
+		return []byte(v.(string)), nil
+	}
+	save := func(lc *Cache) (s string) {
+		lc.Export(func(r Record) bool {
+			b, _ := r.Encode(enc)
+			s += string(b)
+			return true
+		})
+		return
+	}
+	lc := NewLRUCache(1, 10, 0).KeepEncoded()
+	lc.Put("1", "a")
+	lc.Put("2", "b")
+	if s := save(lc); s != "ab" || encodes != 2 {
+		t.Error("case 1 failed", s, encodes)
+	}
+	if s := save(lc); s != "ab" || encodes != 2 { // kept
+		t.Error("case 2 failed", s, encodes)
+	}
+	lc.Put("1", "c")
+	lc.Update("2", func(v interface{}) interface{} { return "d" })
+	if s := save(lc); s != "dc" || encodes != 4 { // "1" is the most recently used
+		t.Error("case 3 failed", s, encodes)
+	}
+
+	// not kept by default
+	lc = NewLRUCache(1, 10, 0)
+	lc.Put("1", "a")
+	save(lc)
+	if s := save(lc); s != "a" || encodes != 6 {
+		t.Error("case 4 failed", s, encodes)
+	}
+}
@@ -0,0 +1,112 @@
+package cache
+
+import "sync"
+
+// max count of writes deferred by a frozen bucket, further writers are blocked until it's thawed
+const freezeQueue = 1024
+
+// a write deferred by a frozen bucket
+type deferred struct {
+	key  string
+	val  interface{}
+	exp  int64
+	cost int64
+	src  Source
+	del  bool
+	tag  interface{}
+}
+
+type freeze struct {
+	ops  []deferred
+	cond *sync.Cond // signaled when thawed
+}
+
+// defer a write until the bucket is thawed, lock of the bucket must be held,
+// returns the freeze deferring it, nil if it's applied
+func (c *Cache) enqueue(op deferred, idx int) *freeze {
+	for f := c.freezes[idx]; f != nil && len(f.ops) >= freezeQueue; f = c.freezes[idx] {
+		f.cond.Wait() // backpressure
+	}
+	if c.freezes[idx] == nil { // thawed while waiting
+		c.apply(op, idx)
+		return nil
+	}
+	c.freezes[idx].ops = append(c.freezes[idx].ops, op)
+	return c.freezes[idx]
+}
+
+// apply a deferred write, lock of the bucket must be held
+func (c *Cache) apply(op deferred, idx int) {
+	c.tags[idx] = op.tag
+	if op.del {
+		c.remove(op.key, idx)
+	} else {
+		c.store(op.key, idx, op.val, op.exp, op.cost, op.src)
+	}
+	c.tags[idx] = nil
+}
+
+// block until the bucket is thawed, lock of the bucket must be held
+func (c *Cache) thawed(idx int) {
+	for f := c.freezes[idx]; f != nil; f = c.freezes[idx] {
+		f.cond.Wait()
+	}
+}
+
+// FreezeShard - defer writes (`Put`/`Del`) to bucket `i` until `Thaw(i)`, while `Get` still works (without LFU promotion),
+// so a snapshot taken over several buckets is point-in-time consistent rather than fuzzy,
+// at most 1024 writes are deferred per bucket, then writers block until it's thawed, so are `PutIf*` and `ReplaceAll`
+// it's no-op if the bucket is already frozen
+func (c *Cache) FreezeShard(i int) {
+	c.lock(i)
+	if c.freezes[i] == nil {
+		c.freezes[i] = &freeze{cond: sync.NewCond(&c.locks[i])}
+	}
+	c.locks[i].Unlock()
+}
+
+// Thaw - apply writes deferred by bucket `i` in order, and unblock the writers
+func (c *Cache) Thaw(i int) {
+	c.lock(i)
+	if f := c.freezes[i]; f != nil {
+		c.freezes[i] = nil
+		for _, op := range f.ops {
+			c.apply(op, i)
+		}
+		f.cond.Broadcast()
+	}
+	c.locks[i].Unlock()
+}
+
+// SnapshotShard - get a copy of the live items of bucket `i`
+// freeze buckets first to get a consistent snapshot over all of them, e.g.
+// for i := 0; i < c.Shards(); i++ { c.FreezeShard(i) }
+func (c *Cache) SnapshotShard(i int) map[string]interface{} {
+	m := make(map[string]interface{})
+	c.lock(i)
+	now := c.clock.Now()
+	for level, inst := range c.insts[i] {
+		if inst == nil {
+			continue
+		}
+		inst.foreach(func(k string, v *wrapper) bool {
+			if _, ok := m[k]; !ok && !c.expired(v, now, level) {
+				m[k] = v.v
+			}
+			return true
+		})
+	}
+	c.locks[i].Unlock()
+	if c.dryRun || c.pipeline != nil {
+		for k, v := range m {
+			if c.dryRun {
+				m[k] = nil
+			} else if v, ok := c.pipeline.decode(v); ok {
+				m[k] = v
+			} else {
+				delete(m, k)
+			}
+		}
+	}
+	return m
+}
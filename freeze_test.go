@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_FreezeShard(t *testing.T) {
+	lc := NewLRUCache(1, 3, time.Second).LFU(1)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.FreezeShard(0)
+	lc.FreezeShard(0)
+	lc.Put("1", "3")
+	lc.Put("4", "4")
+	lc.Del("2")
+	if m := lc.SnapshotShard(0); len(m) != 2 || m["1"] != "1" || m["2"] != "2" {
+		t.Error("case 1 failed", m)
+	}
+	lc.Get("1")
+	lc.Get("1") // not promoted
+	if s := lc.ShardStats(0); s.LFULen != 0 {
+		t.Error("case 2 failed")
+	}
+
+	done := make(chan bool)
+	go func() {
+		done <- lc.PutIfAbsent("2", "5")
+	}()
+	select {
+	case <-done:
+		t.Error("case 3 failed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	lc.Thaw(0)
+	if !<-done {
+		t.Error("case 4 failed")
+	}
+	if m := lc.SnapshotShard(0); len(m) != 3 || m["1"] != "3" || m["2"] != "5" || m["4"] != "4" {
+		t.Error("case 5 failed", m)
+	}
+	lc.Thaw(0)
+
+	lc.FreezeShard(0)
+	go func() {
+		for i := 0; i <= freezeQueue; i++ {
+			lc.Put("5", i)
+		}
+		done <- true
+	}()
+	select {
+	case <-done:
+		t.Error("case 6 failed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	lc.Thaw(0)
+	<-done
+	if v, _ := lc.Get("5"); v != freezeQueue {
+		t.Error("case 7 failed")
+	}
+
+	lc.FreezeShard(0)
+	go func() {
+		lc.ReplaceAll(map[string]interface{}{"6": "6"})
+		done <- true
+	}()
+	select {
+	case <-done:
+		t.Error("case 8 failed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	lc.Thaw(0)
+	<-done
+	if m := lc.SnapshotShard(0); len(m) != 1 || m["6"] != "6" {
+		t.Error("case 9 failed", m)
+	}
+}
@@ -0,0 +1,39 @@
+package cache
+
+// strings longer than it are not interned
+const internMaxLen = 64
+
+// Intern - share one copy of frequently repeated small values (booleans, numbers, short strings) among items,
+// so a million items with value "true" built at runtime don't hold a million copies
+// each bucket keeps a table of at most `n` distinct values, which is reset when it's full
+func (c *Cache) Intern(n int) *Cache {
+	c.internCap = n
+	c.interns = nil
+	if n > 0 {
+		c.interns = make([]map[interface{}]interface{}, len(c.insts))
+	}
+	return c
+}
+
+// returns the shared copy of `v` if it's internable, lock of the bucket must be held
+func (c *Cache) intern(idx int, v interface{}) interface{} {
+	switch s := v.(type) {
+	case string:
+		if len(s) > internMaxLen {
+			return v
+		}
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+	default:
+		return v
+	}
+	tbl := c.interns[idx]
+	if shared, ok := tbl[v]; ok {
+		return shared
+	}
+	if tbl == nil || len(tbl) >= c.internCap {
+		tbl = make(map[interface{}]interface{}, c.internCap)
+		c.interns[idx] = tbl
+	}
+	tbl[v] = v
+	return v
+}
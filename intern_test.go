@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// address of the string data
+func strData(v interface{}) uintptr {
+	s := v.(string)
+	return *(*uintptr)(unsafe.Pointer(&s))
+}
+
+func Test_Intern(t *testing.T) {
+	lc := NewLRUCache(1, 10, time.Second).Intern(2)
+	lc.Put("1", strings.Repeat("t", 4)) // built at runtime
+	lc.Put("2", strings.Repeat("t", 4))
+	v1, _ := lc.Get("1")
+	v2, _ := lc.Get("2")
+	if v1 != "tttt" || strData(v1) != strData(v2) {
+		t.Error("case 1 failed")
+	}
+
+	long := strings.Repeat("t", internMaxLen+1)
+	lc.Put("3", strings.Repeat("t", internMaxLen+1))
+	lc.Put("4", strings.Repeat("t", internMaxLen+1))
+	v3, _ := lc.Get("3")
+	v4, _ := lc.Get("4")
+	if v3 != long || strData(v3) == strData(v4) {
+		t.Error("case 2 failed")
+	}
+
+	lc.Put("5", 12345)
+	lc.Put("6", []int{1}) // not comparable, never interned
+	if len(lc.interns[0]) != 2 {
+		t.Error("case 3 failed")
+	}
+	lc.Put("7", true) // table is full, reset
+	if len(lc.interns[0]) != 1 {
+		t.Error("case 4 failed")
+	}
+	if v, ok := lc.Get("5"); !ok || v != 12345 {
+		t.Error("case 5 failed")
+	}
+
+	lc.PutIfAbsent("8", strings.Repeat("t", 4))
+	lc.PutIfAbsent("9", strings.Repeat("t", 4))
+	v8, _ := lc.Get("8")
+	v9, _ := lc.Get("9")
+	if strData(v8) != strData(v9) {
+		t.Error("case 6 failed")
+	}
+
+	if lc.Intern(0).interns != nil {
+		t.Error("case 7 failed")
+	}
+}
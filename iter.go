@@ -0,0 +1,37 @@
+//go:build go1.23
+
+package cache
+
+import (
+	"iter"
+	"strings"
+)
+
+// All - iterator over keys and values of live items, built on `Range` (so are the other iterators),
+// e.g. `maps.Collect(c.All())`, it's not a point-in-time view of the whole cache
+func (c *Cache) All() iter.Seq2[string, interface{}] {
+	return c.AllPrefix("")
+}
+
+// Keys - iterator over keys of live items, e.g. `slices.Sorted(c.Keys())`
+func (c *Cache) Keys() iter.Seq[string] {
+	return c.KeysPrefix("")
+}
+
+// AllPrefix - iterator over keys and values of live items whose key starts with `prefix`
+func (c *Cache) AllPrefix(prefix string) iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		c.Range(func(e EntryView) bool {
+			return !strings.HasPrefix(e.Key, prefix) || yield(e.Key, e.Value)
+		})
+	}
+}
+
+// KeysPrefix - iterator over keys of live items that start with `prefix`
+func (c *Cache) KeysPrefix(prefix string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		c.Range(func(e EntryView) bool {
+			return !strings.HasPrefix(e.Key, prefix) || yield(e.Key)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+//go:build go1.23
+
+package cache
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func Test_Iterators(t *testing.T) {
+	lc := NewLRUCache(4, 4, time.Second)
+	lc.Put("user:1", 1)
+	lc.Put("user:2", 2)
+	lc.Put("item:1", 3)
+	if m := maps.Collect(lc.All()); len(m) != 3 || m["user:2"] != 2 || m["item:1"] != 3 {
+		t.Error("case 1 failed", m)
+	}
+	if k := slices.Sorted(lc.Keys()); !slices.Equal(k, []string{"item:1", "user:1", "user:2"}) {
+		t.Error("case 2 failed", k)
+	}
+	if m := maps.Collect(lc.AllPrefix("user:")); len(m) != 2 || m["user:1"] != 1 {
+		t.Error("case 3 failed", m)
+	}
+	if k := slices.Collect(lc.KeysPrefix("item:")); !slices.Equal(k, []string{"item:1"}) {
+		t.Error("case 4 failed", k)
+	}
+	n := 0
+	for range lc.All() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Error("case 5 failed")
+	}
+}
@@ -0,0 +1,100 @@
+// Package keylock provides mutexes scoped to string keys, e.g. to serialize
+// read-modify-write or reload of the same key around a cache, while different keys never block each other.
+package keylock
+
+import (
+	"hash/crc32"
+	"sync"
+)
+
+// lock of a key, lives only while someone holds or waits for it
+type entry struct {
+	mu   sync.Mutex
+	refs int // holders and waiters
+}
+
+type shard struct {
+	mu    sync.Mutex
+	locks map[string]*entry
+}
+
+// KeyLock - set of per-key mutexes, striped over shards to reduce contention of the bookkeeping
+type KeyLock struct {
+	shards []shard
+	mask   int
+}
+
+// New - create a KeyLock with `shardCnt` shards (rounded up to power of 2)
+func New(shardCnt int) *KeyLock {
+	n := 1
+	for n < shardCnt {
+		n <<= 1
+	}
+	l := &KeyLock{shards: make([]shard, n), mask: n - 1}
+	for i := range l.shards {
+		l.shards[i].locks = make(map[string]*entry)
+	}
+	return l
+}
+
+func (l *KeyLock) shard(key string) *shard {
+	return &l.shards[int(crc32.ChecksumIEEE([]byte(key)))&l.mask]
+}
+
+// Lock - lock `key`, block until it's available
+func (l *KeyLock) Lock(key string) {
+	s := l.shard(key)
+	s.mu.Lock()
+	e := s.locks[key]
+	if e == nil {
+		e = &entry{}
+		s.locks[key] = e
+	}
+	e.refs++
+	s.mu.Unlock()
+	e.mu.Lock()
+}
+
+// TryLock - lock `key` if it's available, and report whether it succeeded
+func (l *KeyLock) TryLock(key string) bool {
+	s := l.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.locks[key]
+	if e == nil {
+		e = &entry{}
+		s.locks[key] = e
+	}
+	if !e.mu.TryLock() {
+		return false
+	}
+	e.refs++
+	return true
+}
+
+// Unlock - unlock `key`, it's a run-time error if `key` is not locked
+func (l *KeyLock) Unlock(key string) {
+	s := l.shard(key)
+	s.mu.Lock()
+	e := s.locks[key]
+	if e == nil {
+		s.mu.Unlock()
+		panic("keylock: unlock of unlocked key")
+	}
+	if e.refs--; e.refs == 0 {
+		delete(s.locks, key)
+	}
+	s.mu.Unlock()
+	e.mu.Unlock()
+}
+
+// Len - count of keys locked or waited for
+func (l *KeyLock) Len() (n int) {
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		n += len(s.locks)
+		s.mu.Unlock()
+	}
+	return
+}
@@ -0,0 +1,76 @@
+package keylock
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func Test_KeyLock(t *testing.T) {
+	l := New(3)
+	if len(l.shards) != 4 {
+		t.Error("case 1 failed")
+	}
+	l.Lock("1")
+	if l.TryLock("1") {
+		t.Error("case 2 failed")
+	}
+	if !l.TryLock("2") {
+		t.Error("case 3 failed")
+	}
+	if l.Len() != 2 {
+		t.Error("case 4 failed")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		l.Lock("1")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Error("case 5 failed")
+	default:
+	}
+	l.Unlock("1")
+	<-done
+	l.Unlock("1")
+	l.Unlock("2")
+	if l.Len() != 0 {
+		t.Error("case 6 failed")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("case 7 failed")
+		}
+	}()
+	l.Unlock("1")
+}
+
+func Test_KeyLockConcurrent(t *testing.T) {
+	l := New(8)
+	cnts := make([]int, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				k := j % len(cnts)
+				l.Lock(strconv.Itoa(k))
+				cnts[k]++
+				l.Unlock(strconv.Itoa(k))
+			}
+		}()
+	}
+	wg.Wait()
+	for k, n := range cnts {
+		if n != 10000 {
+			t.Error("case 1 failed: ", k, n)
+		}
+	}
+	if l.Len() != 0 {
+		t.Error("case 2 failed")
+	}
+}
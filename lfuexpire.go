@@ -0,0 +1,95 @@
+package cache
+
+// ExpiredPolicy - what `Get` does when it finds the item of upper-level-cache (level-1) of lfu expired
+type ExpiredPolicy int
+
+const (
+	KeepExpired    ExpiredPolicy = iota // keep it in its slot until it's evicted by capacity or swept (default)
+	EvictExpired                        // remove it, so the protected slot is free for the next promotion
+	DemoteExpired                       // move it back to level-0, the value is still there for stale reads
+	RefreshExpired                      // reload it in background and keep it in level-1, see `LFURefresh`
+)
+
+// LFUExpired - set what `Get` does on expired items of upper-level-cache,
+// by default they miss but keep the protected slots, which wastes the capacity of hot items,
+// use `LFURefresh` for `RefreshExpired`, it works only with `LFU`
+func (c *Cache) LFUExpired(p ExpiredPolicy) *Cache {
+	if p != RefreshExpired {
+		c.lfuExpired, c.refresh = p, nil
+	}
+	return c
+}
+
+// LFURefresh - reload expired items of upper-level-cache found by `Get` with `loader` in background,
+// and keep the loaded ones in level-1, since items hot enough to be promoted are worth reloading,
+// the `Get` still misses, concurrent loads of the same key are shared with `GetOrLoadWith`, errors are
+// cached by `LoadErrorTTL` if it's enabled, it works only with `LFU`
+func (c *Cache) LFURefresh(loader func(key string) (interface{}, error)) *Cache {
+	c.lfuExpired, c.refresh = RefreshExpired, loader
+	if loader == nil {
+		c.lfuExpired = KeepExpired
+	}
+	return c
+}
+
+// apply `ExpiredPolicy` to the expired item of level-1, lock of the bucket must be held
+func (c *Cache) expiredLFU(key string, idx int, w *wrapper) {
+	switch c.lfuExpired {
+	case EvictExpired:
+		c.insts[idx][1].del(key)
+		c.drop(key, idx, w, Expired)
+	case DemoteExpired:
+		c.insts[idx][1].del(key)
+		c.set(key, idx, 0, w)
+		if c.tracer != nil {
+			c.tracer.record("demote", key, 0, nil)
+		}
+	case RefreshExpired:
+		c.loadBehind(key, idx, c.refresh, 1, FromRefresh)
+	}
+}
+
+// load key by `loader` in background and put it at `level` by `src`, unless it's being loaded, its error is cached,
+// its loads are limited (see `RefreshLimit`), reports whether it's started, lock of the bucket must be held
+func (c *Cache) loadBehind(key string, idx int, loader func(key string) (interface{}, error), level int, src Source) bool {
+	if _, ok := c.calls[idx][key]; ok {
+		return false // being loaded
+	}
+	if c.errs != nil && c.cachedErr(key, idx) != nil || c.fills != nil && c.limited(key, idx) {
+		return false
+	}
+	cl := c.begin(key, idx)
+	go c.fill(key, idx, cl, loader, func(v interface{}) { c.loadedBehind(key, v, level, src) })
+	return true
+}
+
+// put the value loaded in background at `level`, unless the key is put meanwhile
+func (c *Cache) loadedBehind(key string, val interface{}, level int, src Source) {
+	var exp int64
+	if c.adaptive != nil {
+		exp = c.adapt(key, val)
+	}
+	if c.dryRun {
+		val = nil // keys only
+	} else if c.pipeline != nil {
+		var ok bool
+		if val, ok = c.pipeline.encode(val); !ok {
+			return
+		}
+	}
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	if c.freezes[idx] != nil {
+		c.thawed(idx)
+	}
+	if w, _ := c.peek(key, idx); w != nil || c.tombs != nil && c.buried(key, idx, 0) {
+		return
+	}
+	if c.interns != nil {
+		val = c.intern(idx, val)
+	}
+	w := c.alloc(idx, val, c.now())
+	w.src, w.exp = src, exp
+	c.insert(key, idx, level, w)
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_LFUExpired(t *testing.T) {
+	setup := func(p ExpiredPolicy) (*Cache, *fakeClock) {
+		clk := &fakeClock{}
+		lc := NewLRUCache(1, 3, time.Second).LFU(3).Clock(clk).LFUExpired(p)
+		lc.Put("1", "1")
+		lc.Get("1") // l0 -> l1
+		clk.Add(2 * time.Second)
+		if _, ok := lc.Get("1"); ok {
+			t.Error("case 1 failed")
+		}
+		return lc, clk
+	}
+
+	lc, _ := setup(KeepExpired)
+	if lc.insts[0][1].length() != 1 {
+		t.Error("case 2 failed")
+	}
+	lc, _ = setup(EvictExpired)
+	if lc.insts[0][1].length() != 0 || lc.insts[0][0].length() != 0 {
+		t.Error("case 3 failed")
+	}
+	lc, _ = setup(DemoteExpired)
+	if lc.insts[0][1].length() != 0 || lc.insts[0][0].length() != 1 {
+		t.Error("case 4 failed")
+	}
+
+	// with decay
+	clk := &fakeClock{}
+	lc = NewLRUCache(1, 3, time.Second).LFU(3).Decay(time.Hour, 2).Clock(clk).LFUExpired(EvictExpired)
+	lc.Put("1", "1")
+	lc.Get("1") // l0 -> l1
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); ok || lc.insts[0][1].length() != 0 {
+		t.Error("case 5 failed")
+	}
+}
+
+func Test_LFURefresh(t *testing.T) {
+	clk := &fakeClock{}
+	loaded := make(chan struct{}, 1)
+	lc := NewLRUCache(1, 3, time.Second).LFU(3).Clock(clk).LoadErrorTTL(time.Minute)
+	lc.LFURefresh(func(key string) (interface{}, error) {
+		defer func() { loaded <- struct{}{} }()
+		if key == "2" {
+			return nil, errors.New("load")
+		}
+		return key + "'", nil
+	})
+	lc.Put("1", "1")
+	lc.Get("1") // l0 -> l1
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 1 failed")
+	}
+	<-loaded
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if v, err := lc.Await(ctx, "1"); err != nil || v != "1'" || lc.insts[0][1].hmap["1"] == nil {
+		t.Error("case 2 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != "1'" {
+		t.Error("case 3 failed")
+	}
+
+	lc.Put("2", "2")
+	lc.Get("2")
+	clk.Add(2 * time.Second)
+	lc.Get("2")
+	<-loaded
+	if _, err := lc.GetOrLoadWith("2", func(key string) (interface{}, error) { return "2", nil }); err == nil {
+		t.Error("case 4 failed") // the error is cached
+	}
+	lc.Get("2") // no more loads
+	select {
+	case <-loaded:
+		t.Error("case 5 failed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if lc.LFURefresh(nil).lfuExpired != KeepExpired || lc.LFUExpired(RefreshExpired).lfuExpired != KeepExpired {
+		t.Error("case 6 failed")
+	}
+}
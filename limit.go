@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// interval between loads of keys with the prefix
+type refreshLimit struct {
+	prefix   string
+	interval int64
+}
+
+// RefreshLimit - allow at most one load per key per `interval`, by `GetOrLoadWith` (and `WithTTL`) or `LFURefresh`,
+// a miss of `GetOrLoadWith` within the interval gets the stale (expired) item instead of calling the loader,
+// so many callers hitting a stale key during an expiration wave can't stampede the backend,
+// keys without any item are always loaded
+func (c *Cache) RefreshLimit(interval time.Duration) *Cache {
+	return c.RefreshLimitPrefix("", interval)
+}
+
+// RefreshLimitPrefix - the same as `RefreshLimit` but for keys with `prefix` (a group), the longest matching prefix wins,
+// `0` interval for no limit of the group
+func (c *Cache) RefreshLimitPrefix(prefix string, interval time.Duration) *Cache {
+	for i := range c.limits {
+		if c.limits[i].prefix == prefix {
+			c.limits = append(c.limits[:i], c.limits[i+1:]...)
+			break
+		}
+	}
+	c.limits = append(c.limits, refreshLimit{prefix, int64(interval)})
+	sort.SliceStable(c.limits, func(i, j int) bool { return len(c.limits[i].prefix) > len(c.limits[j].prefix) })
+	if c.fills == nil {
+		c.fills = make([]side[int64], len(c.insts))
+	}
+	return c
+}
+
+// interval between loads of key
+func (c *Cache) limitOf(key string) int64 {
+	for i := range c.limits {
+		if strings.HasPrefix(key, c.limits[i].prefix) {
+			return c.limits[i].interval
+		}
+	}
+	return 0
+}
+
+// whether key is loaded within its interval, lock of the bucket must be held
+func (c *Cache) limited(key string, idx int) bool {
+	at, ok := c.fills[idx].m[key]
+	return ok && c.clock.Now()-at < c.limitOf(key)
+}
+
+// record a load of key, lock of the bucket must be held
+func (c *Cache) filled(key string, idx int) {
+	now := c.clock.Now()
+	c.fills[idx].set(key, now, func(k string, at int64) bool { return now-at >= c.limitOf(k) })
+}
+
+// get the stale item of key if its load is limited
+func (c *Cache) stale(key string) (v interface{}, ok bool) {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	if c.limited(key, idx) {
+		if w, _ := c.stored(key, idx); w != nil {
+			v, ok = w.v, true
+		}
+	}
+	c.locks[idx].Unlock()
+	if ok && c.dryRun {
+		return nil, false
+	}
+	if ok && c.pipeline != nil {
+		v, ok = c.pipeline.decode(v)
+	}
+	return
+}
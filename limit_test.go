@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_RefreshLimit(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 10, time.Second).Clock(clk).RefreshLimit(time.Minute).RefreshLimitPrefix("free:", 0)
+	loads := 0
+	loader := func(key string) (interface{}, error) {
+		loads++
+		return key + strconv.Itoa(loads), nil
+	}
+	if v, _ := lc.GetOrLoadWith("1", loader); v != "11" || loads != 1 {
+		t.Error("case 1 failed")
+	}
+	clk.Add(2 * time.Second) // expired, but loaded within the interval
+	for i := 0; i < 10; i++ {
+		if v, err := lc.GetOrLoadWith("1", loader); err != nil || v != "11" || loads != 1 {
+			t.Error("case 2 failed: ", v, loads)
+		}
+	}
+	clk.Add(time.Minute)
+	if v, _ := lc.GetOrLoadWith("1", loader); v != "12" || loads != 2 {
+		t.Error("case 3 failed")
+	}
+
+	// no item to serve
+	lc.Del("1")
+	if v, _ := lc.GetOrLoadWith("1", loader); v != "13" || loads != 3 {
+		t.Error("case 4 failed")
+	}
+
+	// group without limit
+	lc.GetOrLoadWith("free:1", loader)
+	clk.Add(2 * time.Second)
+	if v, _ := lc.GetOrLoadWith("free:1", loader); v != "free:15" || loads != 5 {
+		t.Error("case 5 failed: ", v)
+	}
+
+	// group with longer limit
+	lc.RefreshLimitPrefix("free:", time.Hour).RefreshLimitPrefix("free:", 10*time.Second)
+	if len(lc.limits) != 2 || lc.limits[0].prefix != "free:" {
+		t.Error("case 6 failed")
+	}
+	clk.Add(2 * time.Second)
+	if v, _ := lc.GetOrLoadWith("free:1", loader); v != "free:15" || loads != 5 {
+		t.Error("case 7 failed: ", v)
+	}
+
+	// purged as it grows
+	for i := 0; i < purgeEvery; i++ {
+		lc.GetOrLoadWith(strconv.Itoa(i+100), loader)
+		if i == 0 {
+			clk.Add(2 * time.Minute)
+		}
+	}
+	if len(lc.fills[0].m) >= purgeEvery {
+		t.Error("case 8 failed: ", len(lc.fills[0].m))
+	}
+}
+
+func Test_RefreshLimitLFU(t *testing.T) {
+	clk := &fakeClock{}
+	loaded := make(chan struct{}, 10)
+	lc := NewLRUCache(1, 3, time.Second).LFU(3).Clock(clk).RefreshLimit(time.Minute).
+		LFURefresh(func(key string) (interface{}, error) {
+			loaded <- struct{}{}
+			return key, nil
+		})
+	lc.GetOrLoadWith("1", func(key string) (interface{}, error) { return key, nil })
+	lc.Get("1") // l0 -> l1
+	clk.Add(2 * time.Second)
+	lc.Get("1") // limited, no refresh
+	select {
+	case <-loaded:
+		t.Error("case 1 failed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Add(time.Minute)
+	lc.Get("1")
+	select {
+	case <-loaded:
+	case <-time.After(time.Second):
+		t.Error("case 2 failed")
+	}
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// an in-flight call of loader
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// a cached error of loader
+type loadErr struct {
+	err error
+	exp int64
+}
+
+// LoadErrorTTL - cache errors of loaders for `ttl` (negative caching), so a failing backend isn't hit by
+// every miss of the key, `GetOrLoadWith` returns the cached error until it expires
+func (c *Cache) LoadErrorTTL(ttl time.Duration) *Cache {
+	c.errTTL = ttl
+	c.errs = nil
+	if ttl > 0 {
+		c.errs = make([]side[loadErr], len(c.insts))
+	}
+	return c
+}
+
+// GetOrLoadWith - get value of key from cache, or load it by `loader` and put it into cache if it's absent (or expired)
+// concurrent misses of the same key wait for a single call of `loader` (singleflight), so a hot key expiring
+// won't send a stampede to the backend, the loader is given per call as call sites may load from different sources
+// errors are returned to all waiters and nothing is put, see `LoadErrorTTL` to cache them
+func (c *Cache) GetOrLoadWith(key string, loader func(key string) (interface{}, error)) (interface{}, error) {
+	return c.load(key, nil, loader, nil)
+}
+
+// GetOrLoadWithTTL - the same as `GetOrLoadWith`, but the loaded item expires after `ttl` (see `PutWithTTL`)
+func (c *Cache) GetOrLoadWithTTL(key string, ttl time.Duration,
+	loader func(key string) (interface{}, error)) (interface{}, error) {
+	return c.load(key, func() int64 { return c.ttl(ttl) }, loader, nil)
+}
+
+// GetOrLoadWithRemoteTTL - the same as `GetOrLoadWith`, but `loader` loads from a remote tier (e.g. GET and PTTL of redis)
+// and returns the remaining ttl of the key there too, the loaded item expires after it (capped by `max` if it's positive),
+// so the local item is never fresher than the remote one, negative ttl (e.g. -1 of PTTL for no expiration)
+// means the remote one never expires, then the item expires after `max`, or follows `expire` of the cache if there's no cap
+func (c *Cache) GetOrLoadWithRemoteTTL(key string, max time.Duration,
+	loader func(key string) (interface{}, time.Duration, error)) (interface{}, error) {
+	var ttl time.Duration // of the call of loader, which puts the item
+	return c.load(key, func() int64 { return c.remoteTTL(ttl, max) }, func(key string) (v interface{}, err error) {
+		v, ttl, err = loader(key)
+		return
+	}, nil)
+}
+
+// expiration of the item loaded with the remaining `ttl` of the remote tier, capped by `max`
+func (c *Cache) remoteTTL(ttl, max time.Duration) int64 {
+	if max > 0 && (ttl < 0 || ttl > max) {
+		ttl = max
+	}
+	if ttl < 0 {
+		return 0 // `expire` of the level
+	}
+	if exp := after(c.clock.Now(), int64(ttl)); exp != 0 {
+		return exp
+	}
+	return -1 // 0 means following `expire` of the level
+}
+
+// internal sub function that get or load, the loaded item expires at what `exp` returns
+// (nil for `expire` of the level, or `AdaptiveTTL`), and is put with `tag`
+func (c *Cache) load(key string, exp func() int64,
+	loader func(key string) (interface{}, error), tag interface{}) (interface{}, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+	if c.fills != nil {
+		if v, ok := c.stale(key); ok {
+			return v, nil
+		}
+	}
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	if cl, ok := c.calls[idx][key]; ok {
+		c.locks[idx].Unlock()
+		<-cl.done
+		return cl.val, cl.err
+	}
+	if c.errs != nil {
+		if err := c.cachedErr(key, idx); err != nil {
+			c.locks[idx].Unlock()
+			return nil, err
+		}
+	}
+	cl := c.begin(key, idx)
+	c.locks[idx].Unlock()
+	c.fill(key, idx, cl, loader, func(v interface{}) {
+		if exp != nil {
+			c.putTagged(key, v, exp(), 0, FromLoader, tag)
+		} else if c.adaptive != nil {
+			c.putTagged(key, v, c.adapt(key, v), 0, FromLoader, tag)
+		} else {
+			c.putTagged(key, v, 0, 0, FromLoader, tag)
+		}
+	})
+	return cl.val, cl.err
+}
+
+// register an in-flight call of loader, lock of the bucket must be held
+func (c *Cache) begin(key string, idx int) *call {
+	if c.calls[idx] == nil {
+		c.calls[idx] = make(map[string]*call)
+	}
+	if c.fills != nil {
+		c.filled(key, idx)
+	}
+	cl := &call{done: make(chan struct{})}
+	if c.onState != nil {
+		c.transit(key, c.stateOf(key, idx), Filling)
+	}
+	c.calls[idx][key] = cl
+	return cl
+}
+
+// run `loader` for the registered call `cl`, the loaded value is stored by `put`
+func (c *Cache) fill(key string, idx int, cl *call,
+	loader func(key string) (interface{}, error), put func(v interface{})) {
+	defer func() {
+		if r := recover(); r != nil {
+			cl.err = fmt.Errorf("cache: loader of %q panicked: %v", key, r)
+			defer panic(r)
+		}
+		c.lock(idx)
+		delete(c.calls[idx], key)
+		if cl.err != nil && c.errs != nil {
+			c.cacheErr(key, idx, cl.err)
+		}
+		if c.onState != nil {
+			if s := c.stateOf(key, idx); s != Ready { // or it's reported by the put
+				c.transit(key, Filling, s)
+			}
+		}
+		c.locks[idx].Unlock()
+		close(cl.done)
+	}()
+	if cl.val, cl.err = loader(key); cl.err == nil {
+		put(cl.val) // before the call is done, so later misses find either of them
+	}
+}
+
+// get the unexpired error of loader, lock of the bucket must be held
+func (c *Cache) cachedErr(key string, idx int) error {
+	e, ok := c.errs[idx].m[key]
+	if !ok {
+		return nil
+	}
+	if c.clock.Now() <= e.exp {
+		return e.err
+	}
+	c.errs[idx].del(key)
+	return nil
+}
+
+// record the error of loader, lock of the bucket must be held
+func (c *Cache) cacheErr(key string, idx int, err error) {
+	now := c.clock.Now()
+	c.errs[idx].set(key, loadErr{err, after(now, int64(c.errTTL))}, func(_ string, e loadErr) bool { return now > e.exp })
+}
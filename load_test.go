@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_GetOrLoadWith(t *testing.T) {
+	lc := NewLRUCache(1, 3, time.Second)
+	var loads int32
+	loader := func(key string) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(10 * time.Millisecond)
+		return "v" + key, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			if v, err := lc.GetOrLoadWith("1", loader); err != nil || v != "v1" {
+				t.Error("case 1 failed")
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	if loads != 1 {
+		t.Error("case 2 failed", loads)
+	}
+	if v, ok := lc.Get("1"); !ok || v != "v1" {
+		t.Error("case 3 failed")
+	}
+	if len(lc.calls[0]) != 0 {
+		t.Error("case 4 failed")
+	}
+
+	errLoad := errors.New("load")
+	if _, err := lc.GetOrLoadWith("2", func(string) (interface{}, error) { return nil, errLoad }); err != errLoad {
+		t.Error("case 5 failed")
+	}
+	if _, ok := lc.Get("2"); ok {
+		t.Error("case 6 failed")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("case 7 failed")
+			}
+		}()
+		lc.GetOrLoadWith("3", func(string) (interface{}, error) { panic("boom") })
+	}()
+	if v, err := lc.GetOrLoadWith("3", loader); err != nil || v != "v3" {
+		t.Error("case 8 failed")
+	}
+}
+
+func Test_GetOrLoadWithTTL(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).Clock(clk).LoadErrorTTL(time.Minute)
+	var loads int
+	errLoad := errors.New("load")
+	loader := func(key string) (interface{}, error) {
+		if loads++; key == "2" {
+			return nil, errLoad
+		}
+		return key, nil
+	}
+	if v, err := lc.GetOrLoadWithTTL("1", time.Hour, loader); err != nil || v != "1" {
+		t.Error("case 1 failed")
+	}
+	clk.Add(time.Minute)
+	if v, err := lc.GetOrLoadWithTTL("1", time.Hour, loader); err != nil || v != "1" || loads != 1 {
+		t.Error("case 2 failed")
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := lc.GetOrLoadWith("2", loader); err != errLoad || loads != 2 {
+			t.Error("case 3 failed")
+		}
+	}
+	clk.Add(time.Minute + 1)
+	if _, err := lc.GetOrLoadWith("2", loader); err != errLoad || loads != 3 {
+		t.Error("case 4 failed")
+	}
+	lc.Put("2", "2")
+	if v, err := lc.GetOrLoadWith("2", loader); err != nil || v != "2" {
+		t.Error("case 5 failed")
+	}
+
+	// saturated instead of overflowing
+	clk.Add(time.Hour)
+	lc.LoadErrorTTL(math.MaxInt64)
+	for i := 0; i < 2; i++ {
+		if _, err := lc.GetOrLoadWith("3", func(key string) (interface{}, error) {
+			loads++
+			return nil, errLoad
+		}); err != errLoad || loads != 4 {
+			t.Error("case 6 failed")
+		}
+	}
+}
+
+func Test_GetOrLoadWithRemoteTTL(t *testing.T) {
+	clk := &fakeClock{}
+	clk.Add(time.Second)
+	lc := NewLRUCache(1, 10, 10*time.Second).Clock(clk)
+	remote := map[string]time.Duration{"1": 2 * time.Second, "2": time.Hour, "3": -1, "4": 0}
+	var loads int
+	errLoad := errors.New("load")
+	loader := func(key string) (interface{}, time.Duration, error) {
+		loads++
+		ttl, ok := remote[key]
+		if !ok {
+			return nil, 0, errLoad
+		}
+		return key, ttl, nil
+	}
+	check := func(n int, key string, max time.Duration, live, dead time.Duration) {
+		lc.Del(key)
+		if v, err := lc.GetOrLoadWithRemoteTTL(key, max, loader); err != nil || v != key {
+			t.Error("case", n, "failed", err)
+		}
+		clk.Add(live)
+		if _, ok := lc.Get(key); !ok {
+			t.Error("case", n, "failed: expired")
+		}
+		clk.Add(dead - live)
+		if _, ok := lc.Get(key); ok {
+			t.Error("case", n, "failed: alive")
+		}
+	}
+	check(1, "1", 0, 2*time.Second, 2*time.Second+1)
+	check(2, "1", time.Second, time.Second, time.Second+1)       // capped
+	check(3, "2", 5*time.Second, 5*time.Second, 5*time.Second+1) // capped
+	check(4, "3", 0, 10*time.Second, 10*time.Second+1)           // never expires in remote
+	check(5, "3", 3*time.Second, 3*time.Second, 3*time.Second+1) // never expires in remote, capped
+	if v, err := lc.GetOrLoadWithRemoteTTL("4", 0, loader); err != nil || v != "4" {
+		t.Error("case 6 failed")
+	}
+	clk.Add(1)
+	if _, ok := lc.Get("4"); ok { // expiring in remote
+		t.Error("case 7 failed")
+	}
+	if _, err := lc.GetOrLoadWithRemoteTTL("5", 0, loader); err != errLoad {
+		t.Error("case 8 failed")
+	}
+	if _, ok := lc.Get("5"); ok || loads != 7 {
+		t.Error("case 9 failed", loads)
+	}
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// a metric family exposed by `WriteOpenMetrics`
+type metric struct {
+	name, typ, help string
+	val             func(s *ShardStats) float64
+}
+
+var metrics = []metric{
+	{"items", "gauge", "Count of items in level-0.", func(s *ShardStats) float64 { return float64(s.Len) }},
+	{"capacity", "gauge", "Capacity of level-0, 0 if it's bounded by budget.", func(s *ShardStats) float64 { return float64(s.Cap) }},
+	{"lfu_items", "gauge", "Count of items in level-1.", func(s *ShardStats) float64 { return float64(s.LFULen) }},
+	{"lfu_capacity", "gauge", "Capacity of level-1.", func(s *ShardStats) float64 { return float64(s.LFUCap) }},
+	{"cost", "gauge", "Total cost of items in level-0.", func(s *ShardStats) float64 { return float64(s.Cost) }},
+	{"budget", "gauge", "Budget of level-0.", func(s *ShardStats) float64 { return float64(s.Budget) }},
+	{"lfu_cost", "gauge", "Total cost of items in level-1.", func(s *ShardStats) float64 { return float64(s.LFUCost) }},
+	{"lfu_budget", "gauge", "Budget of level-1.", func(s *ShardStats) float64 { return float64(s.LFUBudget) }},
+	{"puts", "counter", "Items put.", func(s *ShardStats) float64 { return float64(s.Puts) }},
+	{"hits", "counter", "Reads that found the item.", func(s *ShardStats) float64 { return float64(s.Hits) }},
+	{"misses", "counter", "Reads that missed, including expired ones.", func(s *ShardStats) float64 { return float64(s.Misses) }},
+	{"expired_reads", "counter", "Reads that found the item expired.", func(s *ShardStats) float64 { return float64(s.Expired) }},
+	{"evictions", "counter", "Items evicted by capacity.", func(s *ShardStats) float64 { return float64(s.Evictions) }},
+	{"lock_waits", "counter", "Times the lock of a bucket was contended.", func(s *ShardStats) float64 { return float64(s.LockWaits) }},
+	{"lock_wait_seconds", "counter", "Time waited for the lock of a bucket.", func(s *ShardStats) float64 { return s.LockWait.Seconds() }},
+	{"archived", "counter", "Evicted items handed to the archiver.", func(s *ShardStats) float64 { return float64(s.Archived) }},
+	{"archive_drops", "counter", "Evicted items dropped by the full queue of the archiver.", func(s *ShardStats) float64 { return float64(s.ArchiveDrops) }},
+}
+
+// WriteOpenMetrics - write stats of the registered caches in OpenMetrics text format, without any client library,
+// each metric comes in three families: "cache_global_<name>" summed over all caches,
+// "cache_<name>" by label `cache` (name of registration) and "cache_shard_<name>" by labels `cache` and `shard`,
+// plus "cache_installs" of them by label `source` (see `Source`)
+func WriteOpenMetrics(w io.Writer) error {
+	type group struct {
+		name string
+		st   Stats
+	}
+	var total ShardStats
+	var groups []group
+	for _, name := range Registered() {
+		if c, ok := Lookup(name); ok {
+			g := group{name, c.Stats()}
+			total.add(&g.st.ShardStats)
+			groups = append(groups, g)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	family := func(name, typ, help string) {
+		bw.WriteString("# TYPE " + name + " " + typ + "\n# HELP " + name + " " + help + "\n")
+	}
+	sample := func(name, typ, labels string, v float64) {
+		if typ == "counter" {
+			name += "_total"
+		}
+		if labels != "" {
+			name += "{" + labels + "}"
+		}
+		bw.WriteString(name + " " + strconv.FormatFloat(v, 'g', -1, 64) + "\n")
+	}
+	for _, m := range metrics {
+		family("cache_global_"+m.name, m.typ, m.help)
+		sample("cache_global_"+m.name, m.typ, "", m.val(&total))
+		family("cache_"+m.name, m.typ, m.help)
+		for _, g := range groups {
+			sample("cache_"+m.name, m.typ, label("cache", g.name), m.val(&g.st.ShardStats))
+		}
+		family("cache_shard_"+m.name, m.typ, m.help)
+		for _, g := range groups {
+			for i := range g.st.Shards {
+				sample("cache_shard_"+m.name, m.typ, label("cache", g.name)+","+label("shard", strconv.Itoa(i)), m.val(&g.st.Shards[i]))
+			}
+		}
+	}
+	const installs = "Items installed by each source."
+	family("cache_global_installs", "counter", installs)
+	for src := Source(0); src < sourceCnt; src++ {
+		sample("cache_global_installs", "counter", label("source", src.String()), float64(total.Installs[src]))
+	}
+	family("cache_installs", "counter", installs)
+	for _, g := range groups {
+		for src := Source(0); src < sourceCnt; src++ {
+			sample("cache_installs", "counter", label("cache", g.name)+","+label("source", src.String()), float64(g.st.Installs[src]))
+		}
+	}
+	family("cache_shard_installs", "counter", installs)
+	for _, g := range groups {
+		for i := range g.st.Shards {
+			for src := Source(0); src < sourceCnt; src++ {
+				sample("cache_shard_installs", "counter", label("cache", g.name)+","+label("shard", strconv.Itoa(i))+","+label("source", src.String()),
+					float64(g.st.Shards[i].Installs[src]))
+			}
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func label(name, val string) string {
+	return name + `="` + labelEscaper.Replace(val) + `"`
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_WriteOpenMetrics(t *testing.T) {
+	a, b := NewLRUCache(1, 10, 0), NewLRUCache(2, 10, 0)
+	Register("a", a)
+	Register("b\"", b)
+	defer Unregister("a")
+	defer Unregister("b\"")
+	a.Put("1", 1)
+	a.Get("1")
+	b.Put("1", 1)
+	b.Put("2", 2)
+	b.Get("3")
+
+	var sb strings.Builder
+	if err := WriteOpenMetrics(&sb); err != nil {
+		t.Error("case 1 failed", err)
+	}
+	out := sb.String()
+	for i, line := range []string{
+		"# TYPE cache_global_items gauge\n",
+		"cache_global_items 3\n",
+		"cache_global_puts_total 3\n",
+		`cache_hits_total{cache="a"} 1` + "\n",
+		`cache_misses_total{cache="b\""} 1` + "\n",
+		`cache_shard_items{cache="a",shard="0"} 1` + "\n",
+		`cache_shard_items{cache="b\"",shard="1"} `,
+		`cache_global_installs_total{source="put"} 3` + "\n",
+		`cache_installs_total{cache="a",source="loader"} 0` + "\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Error("case 2 failed", i, line)
+		}
+	}
+	if !strings.HasSuffix(out, "# EOF\n") || strings.Count(out, "# TYPE cache_items gauge\n") != 1 {
+		t.Error("case 3 failed")
+	}
+
+}
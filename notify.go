@@ -0,0 +1,119 @@
+package cache
+
+// KeyspaceEvent - a change of key, named as keyspace notifications of redis:
+// "set", "expire" (put with its own deadline), "del", "expired", "evicted", "rename_from" and "rename_to"
+type KeyspaceEvent struct {
+	Event string
+	Key   string
+	Tag   interface{} // of the write that caused it, see `WithTag`
+}
+
+// Channel - channel that redis publishes the event to, e.g. "__keyspace@0__:foo" with message "set"
+func (e KeyspaceEvent) Channel() string {
+	return "__keyspace@0__:" + e.Key
+}
+
+// EventChannel - channel that redis publishes the key to, e.g. "__keyevent@0__:set" with message "foo"
+func (e KeyspaceEvent) EventChannel() string {
+	return "__keyevent@0__:" + e.Event
+}
+
+type subscription struct {
+	pattern string
+	fn      func(e KeyspaceEvent)
+}
+
+// KeyspaceEvents - call fn for each event of keys matching `pattern`, in glob-style of redis `PSUBSCRIBE`
+// ("*", "?", "[a-z]", "[^a]" and "\" to escape), so consumers of redis keyspace notifications can switch with minimal changes
+// it can be called many times to subscribe different patterns, replacing a item emits only "set",
+// it's called with the lock of the bucket held, it must be fast and must not call back into the cache
+func (c *Cache) KeyspaceEvents(pattern string, fn func(e KeyspaceEvent)) *Cache {
+	c.subs = append(c.subs, subscription{pattern, fn})
+	return c
+}
+
+func (c *Cache) notify(event, key string, tag interface{}) {
+	for i := range c.subs {
+		if globMatch(c.subs[i].pattern, key) {
+			c.subs[i].fn(KeyspaceEvent{event, key, tag})
+		}
+	}
+}
+
+// event of the item leaving cache by `reason`
+func (r EvictReason) event() string {
+	switch r {
+	case Evicted:
+		return "evicted"
+	case Expired:
+		return "expired"
+	}
+	return "del"
+}
+
+// report whether `s` matches glob-style `pattern` of redis, in linear time of `len(pattern) * len(s)` at most,
+// since only the last '*' needs to be backtracked to (two-pointer matching)
+func globMatch(pattern, s string) bool {
+	p, i := 0, 0
+	star, next := -1, 0 // pattern after the last '*', and where `s` resumes when backtracking to it
+	for i < len(s) {
+		if p < len(pattern) && pattern[p] == '*' {
+			for p < len(pattern) && pattern[p] == '*' {
+				p++
+			}
+			star, next = p, i
+			continue
+		}
+		if p < len(pattern) {
+			if np, ok := globOne(pattern, p, s[i]); ok {
+				p, i = np, i+1
+				continue
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		next++ // let the last '*' take one more byte
+		p, i = star, next
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// match byte `c` against the token of `pattern` at `p` (other than '*'), returns the index after the token
+func globOne(pattern string, p int, c byte) (int, bool) {
+	switch pattern[p] {
+	case '?':
+		return p + 1, true
+	case '[':
+		i, not, match := p+1, false, false
+		if i < len(pattern) && pattern[i] == '^' {
+			not, i = true, i+1
+		}
+		for ; i < len(pattern) && pattern[i] != ']'; i++ {
+			if pattern[i] == '\\' && i+1 < len(pattern) {
+				i++
+				match = match || pattern[i] == c
+			} else if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+				lo, hi := pattern[i], pattern[i+2]
+				if lo > hi {
+					lo, hi = hi, lo
+				}
+				match, i = match || lo <= c && c <= hi, i+2
+			} else {
+				match = match || pattern[i] == c
+			}
+		}
+		if i == len(pattern) { // unclosed, treated as closed at end like redis
+			i--
+		}
+		return i + 1, match != not
+	case '\\':
+		if p+1 < len(pattern) {
+			p++
+		}
+	}
+	return p + 1, pattern[p] == c
+}
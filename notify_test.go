@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_KeyspaceEvents(t *testing.T) {
+	clk := &fakeClock{}
+	var evs, users []KeyspaceEvent
+	lc := NewLRUCache(1, 2, time.Second).Clock(clk).Sweep(1).
+		KeyspaceEvents("*", func(e KeyspaceEvent) { evs = append(evs, e) }).
+		KeyspaceEvents("user:*", func(e KeyspaceEvent) { users = append(users, e) })
+	lc.Put("user:1", 1)
+	lc.Put("user:1", 2)
+	lc.PutWithTTL("2", 2, time.Minute)
+	lc.Put("3", 3) // evicts user:1
+	lc.Del("2")
+	clk.Add(2 * time.Second)
+	lc.Get("3") // swept
+	lc.Put("4", 4)
+	lc.Rename("4", "user:4", false)
+
+	exp := []KeyspaceEvent{{"set", "user:1", nil}, {"set", "user:1", nil}, {"set", "2", nil}, {"expire", "2", nil},
+		{"evicted", "user:1", nil}, {"set", "3", nil}, {"del", "2", nil}, {"expired", "3", nil}, {"set", "4", nil},
+		{"rename_from", "4", nil}, {"rename_to", "user:4", nil}}
+	if len(evs) != len(exp) {
+		t.Fatal("case 1 failed: ", evs)
+	}
+	for i := range exp {
+		if evs[i] != exp[i] {
+			t.Error("case 2 failed: ", i, evs[i])
+		}
+	}
+	if len(users) != 4 || users[2] != (KeyspaceEvent{"evicted", "user:1", nil}) || users[3] != (KeyspaceEvent{"rename_to", "user:4", nil}) {
+		t.Error("case 3 failed: ", users)
+	}
+	if e := evs[0]; e.Channel() != "__keyspace@0__:user:1" || e.EventChannel() != "__keyevent@0__:set" {
+		t.Error("case 4 failed")
+	}
+
+	evs = nil
+	lc.ReplaceAll(map[string]interface{}{"user:4": 5})
+	if len(evs) != 1 || evs[0] != (KeyspaceEvent{"set", "user:4", nil}) {
+		t.Error("case 5 failed: ", evs)
+	}
+}
+
+func Test_globMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		match      bool
+	}{
+		{"*", "", true},
+		{"*", "abc", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abbb", false},
+		{"a**", "a", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[b-a]llo", "hallo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{`[\]]`, "]", true},
+		{"user:*:name", "user:1:name", true},
+		{"user:*:name", "user:1:age", false},
+		{"abc", "ab", false},
+		{"*b*c", "abxbyc", true},
+		{"a*b?d", "abxbcd", true},
+		{"[", "a", false},
+		{`a\`, `a\`, true},
+		{"h[ae", "ha", true},
+		{"a*a*a*a*a*a*a*a*a*a*a*a*b", strings.Repeat("a", 100), false}, // no exponential backtracking
+	}
+	for i, c := range cases {
+		if globMatch(c.pattern, c.s) != c.match {
+			t.Error("case ", i, " failed: ", c.pattern, c.s)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package persist saves the items of a cache with their metadata (see `cache.Export`) to a snapshot in gob,
+// and puts them back by `cache.Import` for warm restarts, optionally with cumulative stats (see `WithStats`),
+// apart from the core package so programs not using it don't link encoding/gob
+package persist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/orca-zhang/cache"
+)
+
+// format of snapshots, bumped on incompatible changes
+const snapshotVersion = 1
+
+// wall clock of snapshots, the cache's own `Clock` doesn't survive a restart
+var wallClock = time.Now
+
+// Option - option of `Save` and `Load`
+type Option func(o *options)
+
+type options struct {
+	stats bool
+}
+
+// WithStats - carry cumulative stats and the sketch of `CountDistinct` over the snapshot (see `cache.Carryover`),
+// `Save` writes them only with it, and `Load` adds those in the snapshot to the totals of `Stats` only with it
+func WithStats() Option {
+	return func(o *options) { o.stats = true }
+}
+
+func apply(opts []Option) (o options) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return
+}
+
+// record of an item in the snapshot stream of `Save`
+type record struct {
+	Key   string
+	Val   []byte // gob of the value as interface{}, nil for nil value
+	Level int
+	Age   int64 // nanoseconds since it was put
+	Seen  int64 // nanoseconds since the last access counted in `Freq`
+	TTL   int64 // remaining nanoseconds if it has its own deadline, -1 for never, 0 to follow expiration of the cache
+	Freq  float64
+	Cost  int64
+	Sum   uint32 // crc32 of the fields above
+}
+
+// checksum of the record, so a corrupted record is skipped instead of serving a wrong value
+func (r *record) sum() uint32 {
+	var b [8 * 6]byte
+	binary.LittleEndian.PutUint64(b[0:], uint64(r.Level))
+	binary.LittleEndian.PutUint64(b[8:], uint64(r.Age))
+	binary.LittleEndian.PutUint64(b[16:], uint64(r.Seen))
+	binary.LittleEndian.PutUint64(b[24:], uint64(r.TTL))
+	binary.LittleEndian.PutUint64(b[32:], math.Float64bits(r.Freq))
+	binary.LittleEndian.PutUint64(b[40:], uint64(r.Cost))
+	s := crc32.ChecksumIEEE([]byte(r.Key))
+	s = crc32.Update(s, crc32.IEEETable, r.Val)
+	return crc32.Update(s, crc32.IEEETable, b[:])
+}
+
+// Save - write all live items of `c` with their timestamps and levels to `w` in gob,
+// values are registered by `gob.Register` as any value stored in interface{},
+// those that fail to encode are skipped, see `cache.KeepEncoded` not to encode unchanged values again,
+// buckets are locked one by one, so it's not a point-in-time snapshot unless all buckets are frozen by `FreezeShard` before
+func Save(c *cache.Cache, w io.Writer, opts ...Option) error {
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snapshotVersion); err != nil {
+		return err
+	}
+	if err := enc.Encode(wallClock().UnixNano()); err != nil {
+		return err
+	}
+	var co cache.Carryover
+	if apply(opts).stats {
+		co = c.Carryover()
+	}
+	if err := enc.Encode(&co); err != nil {
+		return err
+	}
+	var err error
+	c.Export(func(r cache.Record) bool {
+		rec := record{Key: r.Key, Level: r.Level, Age: int64(r.Age), Seen: int64(r.Seen), TTL: int64(r.TTL), Freq: r.Freq, Cost: r.Cost}
+		if r.Value != nil {
+			var e error
+			if rec.Val, e = r.Encode(encodeValue); e != nil {
+				return true
+			}
+		}
+		rec.Sum = rec.sum()
+		err = enc.Encode(&rec)
+		return err == nil
+	})
+	return err
+}
+
+// gob of the value as interface{}, in a buffer of its own as it may be kept by `cache.KeepEncoded`
+func encodeValue(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&v)
+	return buf.Bytes(), err
+}
+
+// Load - put items written by `Save` into `c`, keys are re-hashed so the count of buckets may differ,
+// items age by the time passed since saving (e.g. downtime of a restart),
+// items expired (by their own deadlines or expiration of this cache) or with values failing to decode are skipped,
+// items of upper-level-cache stay there if `LFU` is enabled, records failing the checksum are skipped,
+// it returns error only if the stream is broken (e.g. truncated by a crash), items before the error are kept
+func Load(c *cache.Cache, r io.Reader, opts ...Option) error {
+	dec := gob.NewDecoder(r)
+	var ver int
+	if err := dec.Decode(&ver); err != nil {
+		return err
+	}
+	if ver != snapshotVersion {
+		return errors.New("persist: unknown snapshot version")
+	}
+	var saved int64
+	if err := dec.Decode(&saved); err != nil {
+		return err
+	}
+	elapsed := wallClock().UnixNano() - saved
+	if elapsed < 0 { // wall clock stepped back
+		elapsed = 0
+	}
+	var co cache.Carryover
+	if err := dec.Decode(&co); err != nil {
+		return err
+	}
+	if apply(opts).stats {
+		c.AddCarryover(co)
+	}
+	for {
+		var rec record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if rec.Sum != rec.sum() {
+			continue // corrupted
+		}
+		var v interface{}
+		if rec.Val != nil && gob.NewDecoder(bytes.NewReader(rec.Val)).Decode(&v) != nil {
+			continue
+		}
+		c.Import(cache.Record{Key: rec.Key, Value: v, Level: rec.Level, Age: time.Duration(rec.Age), Seen: time.Duration(rec.Seen),
+			TTL: time.Duration(rec.TTL), Freq: rec.Freq, Cost: rec.Cost}, time.Duration(elapsed))
+	}
+}
+
+// SaveFile - `Save` to file `name`, written to a temporary file first then renamed,
+// and the directory is synced after, so a crash leaves either the old snapshot or the new one
+func SaveFile(c *cache.Cache, name string, opts ...Option) error {
+	f, err := os.Create(name + ".tmp")
+	if err != nil {
+		return err
+	}
+	if err = Save(c, f, opts...); err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(name+".tmp", name)
+	}
+	if err != nil {
+		os.Remove(name + ".tmp")
+		return err
+	}
+	if d, e := os.Open(filepath.Dir(name)); e == nil { // best effort, not supported on some platforms
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// LoadFile - `Load` from file `name` into `c`
+func LoadFile(c *cache.Cache, name string, opts ...Option) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return Load(c, f, opts...)
+}
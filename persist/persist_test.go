@@ -0,0 +1,264 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/gob"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/orca-zhang/cache"
+)
+
+type point struct{ X, Y int }
+
+type fakeClock struct {
+	ns int64
+}
+
+func (f *fakeClock) Now() int64 {
+	return atomic.LoadInt64(&f.ns)
+}
+
+func (f *fakeClock) Add(d time.Duration) {
+	atomic.AddInt64(&f.ns, int64(d))
+}
+
+func Test_SaveLoad(t *testing.T) {
+	gob.Register(point{})
+	wall := time.Now()
+	wallClock = func() time.Time { return wall }
+	defer func() { wallClock = time.Now }()
+	clk := &fakeClock{}
+	lc := cache.NewLRUCache(4, 100, time.Second).LFU(10).Clock(clk)
+	lc.Put("old", "old")
+	clk.Add(2 * time.Second)
+	lc.Put("1", 1)
+	lc.Get("1") // l0 -> l1
+	lc.Put("2", point{1, 2})
+	lc.PutWithTTL("3", "3", 0)
+	lc.PutWithTTL("4", "4", 500*time.Millisecond)
+	lc.Put("5", make(chan int)) // can't be encoded
+	lc.Put("6", nil)
+	clk.Add(100 * time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := Save(lc, &buf); err != nil {
+		t.Fatal("case 1 failed: ", err)
+	}
+	snap := buf.Bytes()
+
+	clk2 := &fakeClock{}
+	clk2.Add(time.Hour)
+	lc2 := cache.NewLRUCache(8, 100, time.Second).LFU(10).Clock(clk2)
+	if err := Load(lc2, bytes.NewReader(snap)); err != nil {
+		t.Fatal("case 2 failed: ", err)
+	}
+	views := map[string]cache.EntryView{}
+	lc2.Range(func(e cache.EntryView) bool {
+		views[e.Key] = e
+		return true
+	})
+	if len(views) != 5 {
+		t.Error("case 3 failed: ", views)
+	}
+	if e := views["1"]; e.Value != 1 || e.Level != 1 || e.Age != 100*time.Millisecond || e.TTL != 900*time.Millisecond {
+		t.Error("case 4 failed: ", e)
+	}
+	if e := views["2"]; e.Value != (point{1, 2}) || e.Level != 0 {
+		t.Error("case 5 failed: ", e)
+	}
+	if e := views["3"]; e.Value != "3" || e.TTL != -1 {
+		t.Error("case 6 failed: ", e)
+	}
+	if e := views["4"]; e.Value != "4" || e.TTL != 400*time.Millisecond {
+		t.Error("case 7 failed: ", e)
+	}
+	if e, ok := views["6"]; !ok || e.Value != nil {
+		t.Error("case 8 failed: ", e)
+	}
+
+	// aged by the time passed since saving
+	wall = wall.Add(300 * time.Millisecond)
+	lc2 = cache.NewLRUCache(8, 100, time.Second).LFU(10).Clock(clk2)
+	Load(lc2, bytes.NewReader(snap))
+	if v, ok := lc2.Get("4"); !ok || v != "4" {
+		t.Error("case 15 failed")
+	}
+	clk2.Add(150 * time.Millisecond)
+	if _, ok := lc2.Get("4"); ok {
+		t.Error("case 16 failed")
+	}
+	if _, ok := lc2.Get("1"); !ok {
+		t.Error("case 17 failed")
+	}
+	clk2.Add(500 * time.Millisecond)
+	if _, ok := lc2.Get("1"); ok { // 100ms old when saved, 1s of expiration
+		t.Error("case 18 failed")
+	}
+	wall = wall.Add(time.Second)
+	lc2 = cache.NewLRUCache(8, 100, time.Second).LFU(10).Clock(clk2)
+	Load(lc2, bytes.NewReader(snap))
+	if v, ok := lc2.Get("3"); !ok || v != "3" || lc2.Len() != 1 { // only the one never expiring
+		t.Error("case 19 failed")
+	}
+	wall = wall.Add(-1300 * time.Millisecond)
+
+	// expired by expiration of the new cache
+	lc3 := cache.NewLRUCache(1, 100, 50*time.Millisecond)
+	Load(lc3, bytes.NewReader(snap))
+	if _, ok := lc3.Get("2"); ok {
+		t.Error("case 9 failed")
+	}
+	if v, ok := lc3.Get("3"); !ok || v != "3" {
+		t.Error("case 10 failed")
+	}
+
+	// the most recently used ones are kept
+	lc = cache.NewLRUCache(1, 100, 0)
+	for i := 0; i < 10; i++ {
+		lc.Put(strconv.Itoa(i), i)
+	}
+	lc.Get("0")
+	buf.Reset()
+	Save(lc, &buf)
+	lc2 = cache.NewLRUCache(1, 3, 0)
+	Load(lc2, &buf)
+	for _, k := range []string{"0", "8", "9"} {
+		if _, ok := lc2.Get(k); !ok {
+			t.Error("case 11 failed: ", k)
+		}
+	}
+
+	if err := Load(lc2, bytes.NewReader(snap[:len(snap)-3])); err == nil {
+		t.Error("case 12 failed")
+	}
+	if err := Load(lc2, bytes.NewReader([]byte("broken"))); err == nil {
+		t.Error("case 13 failed")
+	}
+	buf.Reset()
+	gob.NewEncoder(&buf).Encode(snapshotVersion + 1)
+	if err := Load(lc2, &buf); err == nil {
+		t.Error("case 14 failed")
+	}
+}
+
+func Test_LoadChecksum(t *testing.T) {
+	val := func(v interface{}) []byte {
+		var buf bytes.Buffer
+		gob.NewEncoder(&buf).Encode(&v)
+		return buf.Bytes()
+	}
+	snapshot := func(ver int, recs ...record) *bytes.Buffer {
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		enc.Encode(ver)
+		enc.Encode(time.Now().UnixNano())
+		enc.Encode(&cache.Carryover{})
+		for i := range recs {
+			enc.Encode(&recs[i])
+		}
+		return &buf
+	}
+	good := record{Key: "1", Val: val("1"), TTL: -1}
+	good.Sum = good.sum()
+	bad := record{Key: "2", Val: val("2"), TTL: -1}
+	bad.Sum = bad.sum()
+	bad.Val = val("x") // corrupted
+	lc := cache.NewLRUCache(1, 10, 0)
+	if err := Load(lc, snapshot(snapshotVersion, good, bad)); err != nil {
+		t.Error("case 1 failed: ", err)
+	}
+	if v, ok := lc.Get("1"); !ok || v != "1" {
+		t.Error("case 2 failed")
+	}
+	if _, ok := lc.Get("2"); ok {
+		t.Error("case 3 failed")
+	}
+}
+
+func Test_SaveLoadStats(t *testing.T) {
+	lc := cache.NewLRUCache(2, 10, 0).CountDistinct()
+	lc.Put("1", "1")
+	lc.Get("1")
+	lc.Get("2")
+	var buf bytes.Buffer
+	Save(lc, &buf)
+	lc2 := cache.NewLRUCache(2, 10, 0).CountDistinct()
+	Load(lc2, bytes.NewReader(buf.Bytes()), WithStats())
+	if s := lc2.Stats(); s.Hits != 0 || s.Misses != 0 || s.Installs[cache.FromSnapshot] != 1 || s.DistinctKeys != 0 {
+		t.Error("case 1 failed: ", s.ShardStats)
+	}
+
+	buf.Reset()
+	Save(lc, &buf, WithStats())
+	snap := buf.Bytes()
+	lc2 = cache.NewLRUCache(2, 10, 0).CountDistinct()
+	Load(lc2, bytes.NewReader(snap))
+	if s := lc2.Stats(); s.Hits != 0 || s.DistinctKeys != 0 {
+		t.Error("case 2 failed: ", s.ShardStats)
+	}
+	lc2 = cache.NewLRUCache(2, 10, 0).CountDistinct()
+	Load(lc2, bytes.NewReader(snap), WithStats())
+	if s := lc2.Stats(); s.Puts != 2 || s.Hits != 1 || s.Misses != 1 || s.Len != 1 || s.DistinctKeys != 2 {
+		t.Error("case 3 failed: ", s.ShardStats, s.DistinctKeys)
+	}
+}
+
+func Test_SaveLoadFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "cache.snap")
+	lc := cache.NewLRUCache(2, 10, 0)
+	lc.Put("1", "1")
+	if err := SaveFile(lc, name); err != nil {
+		t.Error("case 1 failed: ", err)
+	}
+	lc = cache.NewLRUCache(2, 10, 0)
+	if err := LoadFile(lc, name); err != nil {
+		t.Error("case 2 failed: ", err)
+	}
+	if v, ok := lc.Get("1"); !ok || v != "1" {
+		t.Error("case 3 failed")
+	}
+	if err := LoadFile(lc, name+".none"); err == nil {
+		t.Error("case 4 failed")
+	}
+}
+
+// a value counting how many times it's encoded
+type counted struct{ N int }
+
+var encodes int32
+
+func (c counted) GobEncode() ([]byte, error) {
+	atomic.AddInt32(&encodes, 1)
+	return []byte{byte(c.N)}, nil
+}
+
+func (c *counted) GobDecode(b []byte) error {
+	c.N = int(b[0])
+	return nil
+}
+
+func Test_SaveKeepEncoded(t *testing.T) {
+	gob.Register(counted{})
+	lc := cache.NewLRUCache(1, 10, 0).KeepEncoded()
+	lc.Put("1", counted{1})
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		if err := Save(lc, &buf); err != nil {
+			t.Error("case 1 failed", err)
+		}
+		lc2 := cache.NewLRUCache(1, 10, 0)
+		if err := Load(lc2, &buf); err != nil {
+			t.Error("case 2 failed", err)
+		}
+		if v, ok := lc2.Get("1"); !ok || v != (counted{1}) {
+			t.Error("case 3 failed", v)
+		}
+	}
+	if n := atomic.LoadInt32(&encodes); n != 1 {
+		t.Error("case 4 failed", n)
+	}
+}
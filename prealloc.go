@@ -0,0 +1,41 @@
+package cache
+
+// Prealloc - preallocate nodes and wrappers of all buckets (and levels), and reuse them instead of leaving to gc,
+// so `Get` and `Put` don't allocate in steady state (values boxed into interface{} by callers excluded),
+// for latency-critical workloads, call it after `LFU` and the other options
+// items watched by `ExpireAfter` are not reused until they leave the cache and the timer fires
+func (c *Cache) Prealloc() *Cache {
+	c.wrappers = make([][]*wrapper, len(c.insts))
+	for idx := range c.insts {
+		n := 1 // one more for the new item before the old one is dropped
+		for _, inst := range c.insts[idx] {
+			if inst != nil {
+				c.locks[idx].Lock()
+				inst.prealloc()
+				c.locks[idx].Unlock()
+				if inst.budget <= 0 { // or count of items is unknown
+					n += inst.capacity()
+				}
+			}
+		}
+		slab := make([]wrapper, n)
+		pool := make([]*wrapper, n)
+		for i := range slab {
+			pool[i] = &slab[i]
+		}
+		c.wrappers[idx] = pool
+	}
+	return c
+}
+
+// get a wrapper from the pool of bucket `idx` if any, lock of the bucket must be held
+func (c *Cache) alloc(idx int, v interface{}, now int64) *wrapper {
+	if c.wrappers == nil || len(c.wrappers[idx]) == 0 {
+		return newWrapper(v, now)
+	}
+	pool := c.wrappers[idx]
+	w := pool[len(pool)-1]
+	c.wrappers[idx] = pool[:len(pool)-1]
+	w.v, w.ts, w.fts, w.freq = v, now, now, 1
+	return w
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Prealloc(t *testing.T) {
+	lc := NewLRUCache(4, 16, time.Hour).LFU(8).Prealloc()
+	keys := make([]string, 128)
+	vals := make([]interface{}, len(keys))
+	for i := range keys {
+		keys[i], vals[i] = strconv.Itoa(i), i
+	}
+	for i := range keys {
+		lc.Put(keys[i], vals[i])
+		lc.Get(keys[i])
+	}
+
+	i := 0
+	if n := testing.AllocsPerRun(1000, func() {
+		lc.Get(keys[i%len(keys)])
+		i++
+	}); n != 0 {
+		t.Error("case 1 failed", n)
+	}
+	if n := testing.AllocsPerRun(1000, func() {
+		lc.Put(keys[i%len(keys)], vals[i%len(keys)]) // new keys with eviction, and replacement
+		i++
+	}); n != 0 {
+		t.Error("case 2 failed", n)
+	}
+	if n := testing.AllocsPerRun(1000, func() {
+		lc.Del(keys[i%len(keys)])
+		lc.Put(keys[i%len(keys)], vals[i%len(keys)])
+		i++
+	}); n != 0 {
+		t.Error("case 3 failed", n)
+	}
+
+	for i := range keys {
+		if v, ok := lc.Get(keys[i]); ok && v != vals[i] {
+			t.Error("case 4 failed", keys[i], v)
+		}
+	}
+	lc.Put("a", "a")
+	ch, _ := lc.ExpireAfter("a")
+	lc.Put("a", "b") // the watched one is not reused
+	if !closed(ch, time.Second) {
+		t.Error("case 5 failed")
+	}
+	if v, ok := lc.Get("a"); !ok || v != "b" {
+		t.Error("case 6 failed")
+	}
+
+	lc.Clear() // nodes and wrappers are still reused afterwards
+	if n := testing.AllocsPerRun(1000, func() {
+		lc.Put(keys[i%len(keys)], vals[i%len(keys)])
+		lc.Del(keys[i%len(keys)])
+		i++
+	}); n != 0 {
+		t.Error("case 7 failed", n)
+	}
+}
+
+func Test_concurrentPrealloc(t *testing.T) {
+	lc := NewLRUCache(2, 2, time.Second).LFU(1).Prealloc()
+	var wg sync.WaitGroup
+	for i := 0; i < 10000; i++ {
+		wg.Add(3)
+		k := strconv.Itoa(i % 5)
+		go func() {
+			lc.Put(k, k)
+			wg.Done()
+		}()
+		go func() {
+			if v, ok := lc.Get(k); ok && v != k {
+				t.Error("case 1 failed")
+			}
+			wg.Done()
+		}()
+		go func() {
+			lc.Del(k)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// PrefetchStats - hit-attribution of access-pattern prefetching
+type PrefetchStats struct {
+	Prefetches uint64 // loads started for predicted keys
+	Hits       uint64 // hits served by items installed by prefetching
+}
+
+// keys accessed right after a key, by how many times, in descending order
+type successors struct {
+	keys []string
+	cnts []uint64
+}
+
+// a first-order markov predictor of the order of accesses
+type predictor struct {
+	stats  PrefetchStats // keep first for 64-bit alignment of atomic ops
+	mu     sync.Mutex    // accesses of all buckets are ordered by it
+	last   string
+	seen   bool
+	next   map[string]*successors
+	n      int
+	keys   int
+	loader func(key string) (interface{}, error)
+}
+
+// Prefetch - observe the order in which keys are got, and when key A is hit, load the `n` keys most frequently got
+// right after A with `loader` in background if they're absent, so sequential access patterns (e.g. a list page
+// followed by its details) hit on the following reads, see `PrefetchStats` for how much it pays off,
+// loads are shared with `GetOrLoadWith`, errors are cached by `LoadErrorTTL` and limited by `RefreshLimit`,
+// successors of at most `keys` keys are tracked, the record is reset when it's full,
+// all `Get`s are ordered by a lock of its own, `n` of `0` (or negative) disables it
+func (c *Cache) Prefetch(n, keys int, loader func(key string) (interface{}, error)) *Cache {
+	if n <= 0 || keys <= 0 || loader == nil {
+		c.predictor = nil
+		return c
+	}
+	c.predictor = &predictor{next: make(map[string]*successors), n: n, keys: keys, loader: loader}
+	return c
+}
+
+// PrefetchStats - get hit-attribution of access-pattern prefetching
+func (c *Cache) PrefetchStats() (s PrefetchStats) {
+	if c.predictor != nil {
+		s.Prefetches = atomic.LoadUint64(&c.predictor.stats.Prefetches)
+		s.Hits = atomic.LoadUint64(&c.predictor.stats.Hits)
+	}
+	return
+}
+
+// record an access of key, and return the keys predicted to follow it if it's a hit
+func (p *predictor) observe(key string, hit bool) (keys []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.seen && p.last != key {
+		s, ok := p.next[p.last]
+		if !ok {
+			if len(p.next) >= p.keys {
+				p.next = make(map[string]*successors)
+			}
+			s = &successors{}
+			p.next[p.last] = s
+		}
+		s.add(key, 2*p.n)
+	}
+	p.last, p.seen = key, true
+	if s, ok := p.next[key]; ok && hit {
+		keys = s.keys
+		if len(keys) > p.n {
+			keys = keys[:p.n]
+		}
+		keys = append([]string(nil), keys...) // taken out of the lock
+	}
+	return
+}
+
+// count key, at most `max` keys are counted, the least counted one is replaced by a new one
+// which inherits its count (space-saving), so keys frequent recently can take over
+func (s *successors) add(key string, max int) {
+	i := 0
+	for i < len(s.keys) && s.keys[i] != key {
+		i++
+	}
+	switch {
+	case i < len(s.keys):
+		s.cnts[i]++
+	case len(s.keys) < max:
+		s.keys, s.cnts = append(s.keys, key), append(s.cnts, 1)
+	default:
+		i--
+		s.keys[i] = key
+		s.cnts[i]++
+	}
+	for ; i > 0 && s.cnts[i] > s.cnts[i-1]; i-- {
+		s.keys[i], s.keys[i-1] = s.keys[i-1], s.keys[i]
+		s.cnts[i], s.cnts[i-1] = s.cnts[i-1], s.cnts[i]
+	}
+}
+
+// prefetch the keys predicted to follow key
+func (c *Cache) predict(key string, hit bool) {
+	for _, k := range c.predictor.observe(key, hit) {
+		idx := hashCode(k) & c.mask
+		c.lock(idx)
+		if w, _ := c.peek(k, idx); w == nil && c.loadBehind(k, idx, c.predictor.loader, 0, FromPrefetch) {
+			atomic.AddUint64(&c.predictor.stats.Prefetches, 1)
+		}
+		c.locks[idx].Unlock()
+	}
+}
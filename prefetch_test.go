@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Prefetch(t *testing.T) {
+	loaded := make(chan struct{}, 1)
+	lc := NewLRUCache(1, 10, 0).Prefetch(1, 100, func(key string) (interface{}, error) {
+		defer func() { loaded <- struct{}{} }()
+		return key + "'", nil
+	})
+	lc.Put("list", "list")
+	lc.Put("a", "a")
+	for i := 0; i < 2; i++ {
+		lc.Get("list")
+		lc.Get("a")
+	}
+	if s := lc.PrefetchStats(); s.Prefetches != 0 { // all present
+		t.Error("case 1 failed", s)
+	}
+
+	lc.Del("a")
+	lc.Get("list") // "a" follows
+	<-loaded
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if v, err := lc.Await(ctx, "a"); err != nil || v != "a'" {
+		t.Error("case 2 failed")
+	}
+	if src, _ := lc.SourceOf("a"); src != FromPrefetch {
+		t.Error("case 3 failed")
+	}
+	if v, ok := lc.Get("a"); !ok || v != "a'" {
+		t.Error("case 4 failed")
+	}
+	if s := lc.PrefetchStats(); s.Prefetches != 1 || s.Hits != 2 { // by `Await` and `Get`
+		t.Error("case 5 failed", s)
+	}
+
+	// not on misses
+	lc.Del("list")
+	lc.Del("a")
+	lc.Get("list")
+	if s := lc.PrefetchStats(); s.Prefetches != 1 {
+		t.Error("case 6 failed", s)
+	}
+
+	if lc.Prefetch(0, 100, nil).predictor != nil || lc.PrefetchStats() != (PrefetchStats{}) {
+		t.Error("case 7 failed")
+	}
+}
+
+func Test_predictor(t *testing.T) {
+	s := &successors{}
+	for _, k := range []string{"a", "a", "b", "c"} {
+		s.add(k, 2)
+	}
+	if !reflect.DeepEqual(s.keys, []string{"a", "c"}) || !reflect.DeepEqual(s.cnts, []uint64{2, 2}) {
+		t.Error("case 1 failed", s)
+	}
+	s.add("c", 2)
+	if !reflect.DeepEqual(s.keys, []string{"c", "a"}) {
+		t.Error("case 2 failed", s)
+	}
+
+	p := &predictor{next: make(map[string]*successors), n: 1, keys: 2}
+	for _, k := range []string{"a", "b", "a", "c", "a", "c"} {
+		p.observe(k, true)
+	}
+	if got := p.observe("a", true); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Error("case 3 failed", got)
+	}
+	if got := p.observe("a", false); got != nil {
+		t.Error("case 4 failed", got)
+	}
+	p.observe("b", true)
+	p.observe("d", true) // "b" is the 3rd key, the record is reset
+	if _, ok := p.next["a"]; ok || len(p.next) != 1 {
+		t.Error("case 5 failed", p.next)
+	}
+}
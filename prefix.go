@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+)
+
+// PrefixStat - statistics of keys with the same prefix
+type PrefixStat struct {
+	Prefix  string
+	Entries int    // count of live items
+	Hits    uint64 // recent hits, only with `TrackPrefixes`
+	Misses  uint64 // recent misses, only with `TrackPrefixes`
+}
+
+// hits and misses of a key
+type access struct {
+	hits, misses uint64
+}
+
+// TrackPrefixes - record recent hits and misses by key, so `StatsByPrefix` can break them down by prefix
+// each bucket records at most `n` distinct keys, the record is reset when it's full
+func (c *Cache) TrackPrefixes(n int) *Cache {
+	c.accessCap = n
+	c.access = nil
+	if n > 0 {
+		c.access = make([]map[string]*access, len(c.insts))
+	}
+	return c
+}
+
+// record an access of key, lock of the bucket must be held
+func (c *Cache) track(key string, idx int, hit bool) {
+	tbl := c.access[idx]
+	a, ok := tbl[key]
+	if !ok {
+		if tbl == nil || len(tbl) >= c.accessCap {
+			tbl = make(map[string]*access)
+			c.access[idx] = tbl
+		}
+		a = &access{}
+		tbl[key] = a
+	}
+	if hit {
+		a.hits++
+	} else {
+		a.misses++
+	}
+}
+
+// prefix of key before the `depth`-th delimiter (or before the last one if there're fewer), "" if there's none
+func prefixOf(key, delim string, depth int) string {
+	end := -1
+	for i, off := 0, 0; i < depth; i++ {
+		j := strings.Index(key[off:], delim)
+		if j < 0 {
+			break
+		}
+		end, off = off+j, off+j+len(delim)
+	}
+	if end < 0 {
+		return ""
+	}
+	return key[:end]
+}
+
+// StatsByPrefix - aggregate count of live items, recent hits and misses by key prefix, sorted by count of items,
+// e.g. key "user:123:profile" is counted in "user" with `delim` ":" and `depth` 1, or in "user:123" with depth 2
+// so it's easy to see which feature's keys are consuming the cache without instrumenting every caller
+func (c *Cache) StatsByPrefix(delim string, depth int) []PrefixStat {
+	m := make(map[string]*PrefixStat)
+	stat := func(key string) *PrefixStat {
+		p := prefixOf(key, delim, depth)
+		s, ok := m[p]
+		if !ok {
+			s = &PrefixStat{Prefix: p}
+			m[p] = s
+		}
+		return s
+	}
+	for idx := range c.insts {
+		c.lock(idx)
+		now := c.clock.Now()
+		for level, inst := range c.insts[idx] {
+			if inst == nil {
+				continue
+			}
+			inst.foreach(func(k string, v *wrapper) bool {
+				if c.expired(v, now, level) {
+					return true
+				}
+				if level == 1 {
+					if _, dup := c.insts[idx][0].hmap[k]; dup { // counted in level-0
+						return true
+					}
+				}
+				stat(k).Entries++
+				return true
+			})
+		}
+		if c.access != nil {
+			for k, a := range c.access[idx] {
+				s := stat(k)
+				s.Hits += a.hits
+				s.Misses += a.misses
+			}
+		}
+		c.locks[idx].Unlock()
+	}
+	res := make([]PrefixStat, 0, len(m))
+	for _, s := range m {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Entries != res[j].Entries {
+			return res[i].Entries > res[j].Entries
+		}
+		return res[i].Prefix < res[j].Prefix
+	})
+	return res
+}
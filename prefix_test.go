@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_prefixOf(t *testing.T) {
+	cases := []struct {
+		key, delim string
+		depth      int
+		prefix     string
+	}{
+		{"user:123:profile", ":", 1, "user"},
+		{"user:123:profile", ":", 2, "user:123"},
+		{"user:123:profile", ":", 3, "user:123"},
+		{"user:123", ":", 2, "user"},
+		{"user", ":", 1, ""},
+		{"a::b::c", "::", 2, "a::b"},
+		{"user:123", ":", 0, ""},
+	}
+	for i, cs := range cases {
+		if p := prefixOf(cs.key, cs.delim, cs.depth); p != cs.prefix {
+			t.Error("case ", i, " failed: ", p)
+		}
+	}
+}
+
+func Test_StatsByPrefix(t *testing.T) {
+	lc := NewLRUCache(4, 10, time.Second).LFU(10)
+	lc.Put("user:1", 1)
+	lc.Put("user:2", 2)
+	lc.Put("item:1", 1)
+	lc.Get("user:1") // l0 -> l1
+	lc.Put("user:1", 1)
+	res := lc.StatsByPrefix(":", 1)
+	if len(res) != 2 || res[0] != (PrefixStat{Prefix: "user", Entries: 2}) || res[1] != (PrefixStat{Prefix: "item", Entries: 1}) {
+		t.Error("case 1 failed: ", res)
+	}
+
+	lc.TrackPrefixes(2)
+	lc.Get("user:1")
+	lc.Get("user:3")
+	lc.Get("order:1")
+	res = lc.StatsByPrefix(":", 1)
+	m := map[string]PrefixStat{}
+	for _, s := range res {
+		m[s.Prefix] = s
+	}
+	if m["user"].Hits != 1 || m["user"].Misses != 1 || m["order"].Misses != 1 || m["order"].Entries != 0 {
+		t.Error("case 2 failed: ", res)
+	}
+}
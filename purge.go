@@ -0,0 +1,40 @@
+package cache
+
+// side tables of keys of a bucket (tombstones, errors of loaders, ...) purge stale entries each time they grow by this count
+const purgeEvery = 1024
+
+// side table of keys of a bucket, e.g. tombstones or errors of loaders, lock of the bucket must be held
+type side[V any] struct {
+	m    map[string]V
+	mark int // least size since the last purge
+}
+
+// set the entry of key, and purge the stale entries once the table has grown by another `purgeEvery` entries
+// since the last purge, so overwriting keys of a table of a multiple of `purgeEvery` entries doesn't purge it each time
+func (s *side[V]) set(key string, v V, stale func(k string, v V) bool) {
+	if s.m == nil {
+		s.m = make(map[string]V)
+	}
+	s.m[key] = v
+	if len(s.m) >= s.mark+purgeEvery {
+		s.prune(stale)
+	}
+}
+
+// delete the entry of key
+func (s *side[V]) del(key string) {
+	delete(s.m, key)
+	if len(s.m) < s.mark {
+		s.mark = len(s.m)
+	}
+}
+
+// delete the entries that `stale` reports, `stale` may release what a stale entry holds
+func (s *side[V]) prune(stale func(k string, v V) bool) {
+	for k, v := range s.m {
+		if stale(k, v) {
+			delete(s.m, k)
+		}
+	}
+	s.mark = len(s.m)
+}
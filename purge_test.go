@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_side(t *testing.T) {
+	var s side[int]
+	scans := 0
+	stale := func(k string, v int) bool {
+		scans++
+		return v%2 == 0
+	}
+	for i := 0; i < purgeEvery-1; i++ {
+		s.set(strconv.Itoa(i), i, stale)
+	}
+	if len(s.m) != purgeEvery-1 || scans != 0 {
+		t.Error("case 1 failed")
+	}
+	s.set("last", 1, stale)
+	if len(s.m) != purgeEvery/2 || scans != purgeEvery {
+		t.Error("case 2 failed: ", len(s.m), scans)
+	}
+
+	// overwriting keys doesn't purge
+	for i := 0; i < purgeEvery; i++ {
+		s.set("last", i, stale)
+	}
+	if scans != purgeEvery {
+		t.Error("case 3 failed: ", scans)
+	}
+	// grown by another `purgeEvery` entries since the least size
+	s.del("last")
+	for i := 0; i < purgeEvery-1; i++ {
+		s.set("new"+strconv.Itoa(i), 1, stale)
+	}
+	if scans != purgeEvery {
+		t.Error("case 4 failed: ", scans)
+	}
+	s.set("new", 1, stale)
+	if scans != purgeEvery+len(s.m) {
+		t.Error("case 5 failed: ", scans)
+	}
+
+	s.prune(func(k string, v int) bool { return true })
+	if len(s.m) != 0 || s.mark != 0 {
+		t.Error("case 6 failed")
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import "time"
+
+// EntryView - a live item with its metadata, got by `Range`
+type EntryView struct {
+	Key    string
+	Value  interface{}
+	Age    time.Duration // since it was put, 0 if neither expiration nor `Decay` is enabled (timestamps aren't recorded)
+	TTL    time.Duration // remaining time to live, -1 if it never expires
+	Level  int           // 0 for normal lru, 1 for upper-level-cache of lfu
+	Freq   float64       // decayed access frequency, only used with `Decay`
+	Source Source        // the path that installed it
+}
+
+// Range - call f sequentially for each live item with its metadata, until f returns false
+// views of a bucket are copied while it's locked, then f is called without any lock held,
+// so f may call back into the cache, but it's not a point-in-time view of the whole cache (see `FreezeShard`)
+func (c *Cache) Range(f func(e EntryView) bool) {
+	var views []EntryView
+	for idx := range c.insts {
+		views = c.views(idx, views[:0])
+		for i := range views {
+			if c.dryRun {
+				views[i].Value = nil
+			} else if c.pipeline != nil {
+				var ok bool
+				if views[i].Value, ok = c.pipeline.decode(views[i].Value); !ok {
+					continue
+				}
+			}
+			if !f(views[i]) {
+				return
+			}
+		}
+	}
+}
+
+// append views of live items of bucket `idx` to `views`
+func (c *Cache) views(idx int, views []EntryView) []EntryView {
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	now, stamped := c.clock.Now(), c.now() != 0
+	for level, inst := range c.insts[idx] {
+		if inst == nil {
+			continue
+		}
+		inst.foreach(func(k string, w *wrapper) bool {
+			if c.expired(w, now, level) {
+				return true
+			}
+			if level == 1 {
+				if e, dup := c.insts[idx][0].hmap[k]; dup && !c.expired(e.v, now, 0) {
+					return true // the newer one in level-0
+				}
+			}
+			e := EntryView{Key: k, Value: w.v, TTL: -1, Level: level, Freq: w.freq, Source: w.src}
+			if stamped {
+				e.Age = time.Duration(now - w.ts)
+			}
+			if c.mortal(w, level) {
+				e.TTL = c.remaining(w, level) - 1
+			}
+			views = append(views, e)
+			return true
+		})
+	}
+	return views
+}
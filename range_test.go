@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Range(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(4, 100, time.Second).LFU(10).Clock(clk)
+	lc.Put("old", "old")
+	clk.Add(2 * time.Second)
+	lc.Put("1", 1)
+	lc.Get("1") // l0 -> l1
+	lc.PutWithTTL("2", 2, 0)
+	clk.Add(100 * time.Millisecond)
+
+	views := map[string]EntryView{}
+	lc.Range(func(e EntryView) bool {
+		if _, dup := views[e.Key]; dup {
+			t.Error("case 1 failed: ", e.Key)
+		}
+		views[e.Key] = e
+		return true
+	})
+	if len(views) != 2 {
+		t.Error("case 2 failed: ", views)
+	}
+	if e := views["1"]; e.Value != 1 || e.Level != 1 || e.Age != 100*time.Millisecond || e.TTL != 900*time.Millisecond {
+		t.Error("case 3 failed: ", e)
+	}
+	if e := views["2"]; e.Value != 2 || e.Level != 0 || e.TTL != -1 {
+		t.Error("case 4 failed: ", e)
+	}
+
+	n, del := 0, ""
+	lc.Range(func(e EntryView) bool {
+		n++
+		del = e.Key
+		lc.Del(e.Key) // no lock is held
+		return false
+	})
+	if _, ok := lc.Get(del); n != 1 || ok {
+		t.Error("case 5 failed")
+	}
+
+	lc = NewLRUCache(1, 3, 0)
+	lc.Put("1", 1)
+	lc.Range(func(e EntryView) bool {
+		if e.Age != 0 || e.TTL != -1 {
+			t.Error("case 6 failed: ", e)
+		}
+		return true
+	})
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+)
+
+// process-wide named caches, see `Register`
+var registry = struct {
+	sync.RWMutex
+	caches map[string]*Cache
+}{caches: make(map[string]*Cache)}
+
+// Register - add cache `c` to the process-wide registry by `name`, replacing the former one of the same name,
+// so that a service running many caches can inspect and manage them together
+func Register(name string, c *Cache) {
+	registry.Lock()
+	registry.caches[name] = c
+	registry.Unlock()
+}
+
+// Unregister - remove the cache of `name` from the registry
+func Unregister(name string) {
+	registry.Lock()
+	delete(registry.caches, name)
+	registry.Unlock()
+}
+
+// Lookup - get the registered cache of `name`
+func Lookup(name string) (*Cache, bool) {
+	registry.RLock()
+	c, ok := registry.caches[name]
+	registry.RUnlock()
+	return c, ok
+}
+
+// Registered - names of all registered caches in ascending order
+func Registered() []string {
+	registry.RLock()
+	names := make([]string, 0, len(registry.caches))
+	for name := range registry.caches {
+		names = append(names, name)
+	}
+	registry.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// AllStats - stats summed over all registered caches, and the summary of each cache by name
+func AllStats() (total ShardStats, each map[string]ShardStats) {
+	each = make(map[string]ShardStats)
+	for _, name := range Registered() {
+		if c, ok := Lookup(name); ok {
+			s := c.Stats().ShardStats
+			each[name] = s
+			total.add(&s)
+		}
+	}
+	return
+}
+
+// PurgeAll - `Clear` all registered caches
+func PurgeAll() {
+	for _, name := range Registered() {
+		if c, ok := Lookup(name); ok {
+			c.Clear()
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+import "testing"
+
+func Test_Registry(t *testing.T) {
+	a, b := NewLRUCache(1, 10, 0), NewLRUCache(2, 10, 0)
+	Register("b", b)
+	Register("a", a)
+	defer Unregister("a")
+	defer Unregister("b")
+	if n := Registered(); len(n) != 2 || n[0] != "a" || n[1] != "b" {
+		t.Error("case 1 failed: ", n)
+	}
+	if c, ok := Lookup("a"); !ok || c != a {
+		t.Error("case 2 failed")
+	}
+	if _, ok := Lookup("none"); ok {
+		t.Error("case 3 failed")
+	}
+
+	a.Put("1", 1)
+	b.Put("1", 1)
+	b.Put("2", 2)
+	b.Get("1")
+	total, each := AllStats()
+	if total.Len != 3 || total.Puts != 3 || total.Hits != 1 || each["a"].Len != 1 || each["b"].Len != 2 {
+		t.Error("case 4 failed: ", total, each)
+	}
+
+	Unregister("a")
+	if n := Registered(); len(n) != 1 || n[0] != "b" {
+		t.Error("case 5 failed: ", n)
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+// lock buckets `i` and `j` in ascending order, waiting for them to be thawed
+func (c *Cache) lock2(i, j int) {
+	if i > j {
+		i, j = j, i
+	}
+	for {
+		c.lock(i)
+		if i != j {
+			c.lock(j)
+		}
+		f := i
+		if c.freezes[i] == nil {
+			if f = j; c.freezes[j] == nil {
+				return
+			}
+		}
+		if o := i + j - f; o != f { // wait for it without holding the other lock, then start over
+			c.locks[o].Unlock()
+		}
+		c.thawed(f)
+		c.locks[f].Unlock()
+	}
+}
+
+func (c *Cache) unlock2(i, j int) {
+	c.locks[i].Unlock()
+	if i != j {
+		c.locks[j].Unlock()
+	}
+}
+
+// Rename - atomically move the live item of `oldKey` to `newKey` (even across buckets),
+// keeping its value, expiration and frequency state, e.g. when canonical identifiers change
+// returns false if `oldKey` is absent (or expired), or `newKey` is present (and live) while `overwrite` is false
+// channels got by `ExpireAfter(oldKey)` are closed as the old key leaves the cache
+func (c *Cache) Rename(oldKey, newKey string, overwrite bool) bool {
+	if oldKey == newKey {
+		_, ok := c.Get(oldKey)
+		return ok
+	}
+	i, j := hashCode(oldKey)&c.mask, hashCode(newKey)&c.mask
+	c.lock2(i, j)
+	defer c.unlock2(i, j)
+	w, level := c.peek(oldKey, i)
+	if w == nil {
+		return false
+	}
+	if cur, _ := c.peek(newKey, j); cur != nil && !overwrite || c.tombs != nil && c.buried(newKey, j, w.ver) {
+		return false
+	}
+	from := Absent
+	if c.onState != nil {
+		from = c.stateOf(newKey, j)
+	}
+	c.insts[i][level].del(oldKey)
+	c.remove(oldKey, i) // stale one of the other level if any
+	if w.watch != nil { // the item leaves as the old key, but it's not dropped
+		w.watch.close()
+		w.watch = nil
+	}
+	for _, inst := range c.insts[j] {
+		if inst == nil {
+			continue
+		}
+		if v, ok := inst.del(newKey); ok {
+			c.drop(newKey, j, v, Replaced)
+		}
+	}
+	c.set(newKey, j, level, w)
+	if len(c.waiters[j]) != 0 {
+		c.wake(newKey, j)
+	}
+	if c.onState != nil {
+		c.transit(oldKey, Ready, Evicting)
+		c.transit(newKey, from, Ready)
+	}
+	if c.subs != nil {
+		c.notify("rename_from", oldKey, nil)
+		c.notify("rename_to", newKey, nil)
+	}
+	if c.tracer != nil {
+		c.tracer.record("rename_from", oldKey, 0, nil)
+		c.tracer.record("rename_to", newKey, 0, nil)
+	}
+	return true
+}
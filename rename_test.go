@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Rename(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(4, 3, time.Second).LFU(3).Clock(clk)
+	if lc.Rename("1", "2", true) {
+		t.Error("case 1 failed")
+	}
+	lc.Put("1", "1")
+	lc.Get("1") // l0 -> l1
+	lc.Put("2", "2")
+	ch, _ := lc.ExpireAfter("1")
+	if lc.Rename("1", "2", false) {
+		t.Error("case 2 failed")
+	}
+	clk.Add(500 * time.Millisecond)
+	if !lc.Rename("1", "2", true) {
+		t.Error("case 3 failed")
+	}
+	if !closed(ch, time.Second) {
+		t.Error("case 4 failed")
+	}
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 5 failed")
+	}
+	if v, ok := lc.Get("2"); !ok || v != "1" {
+		t.Error("case 6 failed")
+	}
+	idx := hashCode("2") & lc.mask
+	if _, ok := lc.insts[idx][1].hmap["2"]; !ok { // level kept
+		t.Error("case 7 failed")
+	}
+	clk.Add(600 * time.Millisecond) // expiration kept
+	if _, ok := lc.Get("2"); ok {
+		t.Error("case 8 failed")
+	}
+
+	lc.Put("3", "3")
+	if !lc.Rename("3", "3", false) || lc.Rename("4", "4", false) {
+		t.Error("case 9 failed")
+	}
+	lc.FreezeShard(int(hashCode("5") & lc.mask))
+	done := make(chan bool)
+	go func() {
+		done <- lc.Rename("3", "5", false)
+	}()
+	select {
+	case <-done:
+		t.Error("case 10 failed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	lc.Thaw(int(hashCode("5") & lc.mask))
+	if !<-done {
+		t.Error("case 11 failed")
+	}
+}
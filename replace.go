@@ -0,0 +1,108 @@
+package cache
+
+// ReplaceAll - atomically replace the whole content of cache with `entries`
+// fresh buckets are built off to the side and swapped in while holding all locks,
+// so a `Get` sees either the old content or the new one, never a mix of them
+// entries beyond the capacity of a bucket are evicted as usual
+func (c *Cache) ReplaceAll(entries map[string]interface{}) {
+	insts := make([][2]*lru, len(c.insts))
+	for i := range insts {
+		c.locks[i].Lock()
+		for level, inst := range c.insts[i] {
+			if inst != nil {
+				insts[i][level] = inst.fresh()
+			}
+		}
+		c.locks[i].Unlock()
+	}
+
+	now := c.now()
+	for k, v := range entries {
+		if c.pipeline != nil {
+			var ok bool
+			if v, ok = c.pipeline.encode(v); !ok {
+				continue
+			}
+		}
+		w, inst := newWrapper(v, now), insts[hashCode(k)&c.mask][0]
+		w.src = FromReplace
+		if inst.budget <= 0 {
+			inst.put(k, w)
+		} else if w.cost = costOf(v); w.cost <= inst.budget {
+			inst.trim(w.cost, nil)
+			inst.putCost(k, w, w.cost)
+		}
+	}
+
+	// always lock in ascending order
+	for i := 0; i < len(c.locks); i++ {
+		c.locks[i].Lock()
+		if c.freezes[i] != nil { // wait for it without holding other locks, then start over
+			for j := i - 1; j >= 0; j-- {
+				c.locks[j].Unlock()
+			}
+			c.thawed(i)
+			c.locks[i].Unlock()
+			i = -1
+		}
+	}
+	for i := range insts {
+		insts[i], c.insts[i] = c.insts[i], insts[i] // keep the old ones to drop
+		c.locks[i].cnts.installs[FromReplace] += uint64(c.insts[i][0].length())
+		if c.trash != nil {
+			for k, t := range c.trash[i].m {
+				c.dropFrom(k, i, t.w, Deleted, Hidden)
+			}
+			c.trash[i] = side[trashed]{}
+		}
+		for k := range c.waiters[i] {
+			if _, ok := entries[k]; ok {
+				c.wake(k, i)
+			}
+		}
+		if c.onState != nil {
+			for k := range c.insts[i][0].hmap {
+				from := Absent
+				for _, inst := range insts[i] {
+					if inst == nil {
+						continue
+					}
+					if _, ok := inst.hmap[k]; ok {
+						from = Ready
+					}
+				}
+				c.transit(k, from, Ready)
+			}
+		}
+		if c.subs != nil {
+			for k := range c.insts[i][0].hmap {
+				c.notify("set", k, nil)
+			}
+		}
+		if c.tracer != nil {
+			for k := range c.insts[i][0].hmap {
+				c.tracer.record("set", k, FromReplace, nil)
+			}
+		}
+	}
+
+	if c.departs() || c.wrappers != nil { // recycle wrappers of the old items too
+		for i := range insts {
+			for _, inst := range insts[i] {
+				if inst != nil {
+					inst.foreach(func(k string, v *wrapper) bool {
+						reason := Deleted
+						if _, ok := entries[k]; ok {
+							reason = Replaced
+						}
+						c.drop(k, i, v, reason)
+						return true
+					})
+				}
+			}
+		}
+	}
+	for i := range c.locks {
+		c.locks[i].Unlock()
+	}
+}
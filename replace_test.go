@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_ReplaceAll(t *testing.T) {
+	lc := NewLRUCache(4, 2, time.Second).LFU(2)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Get("2") // l0 -> l1
+	lc.ReplaceAll(map[string]interface{}{"2": "b", "3": "c"})
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 1 failed")
+	}
+	if v, ok := lc.Get("2"); !ok || v != "b" {
+		t.Error("case 2 failed")
+	}
+	if v, ok := lc.Get("3"); !ok || v != "c" {
+		t.Error("case 3 failed")
+	}
+	if lc.insts[0][1] == nil || lc.insts[0][1].capacity() != 2 {
+		t.Error("case 4 failed")
+	}
+
+	lc.ReplaceAll(nil)
+	for _, k := range []string{"1", "2", "3"} {
+		if _, ok := lc.Get(k); ok {
+			t.Error("case 5 failed: ", k)
+		}
+	}
+}
+
+func Test_ReplaceAllDeparts(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		async []string
+		tags  []string
+	)
+	lc := NewLRUCache(4, 2, time.Second).OnEvictAsync(func(key string, val interface{}, reason EvictReason) {
+		mu.Lock()
+		async = append(async, key+" "+reason.String())
+		mu.Unlock()
+	}, 2, 0, true).OnEvictTag(func(key string, val interface{}, reason EvictReason, tag interface{}) {
+		tags = append(tags, key+" "+reason.String())
+	})
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.ReplaceAll(map[string]interface{}{"2": "b"})
+	lc.Clear()
+	lc.Close()
+	sort.Strings(async)
+	sort.Strings(tags)
+	if want := "[1 deleted 2 deleted 2 replaced]"; fmt.Sprint(async) != want {
+		t.Error("case 1 failed: ", async)
+	}
+	if want := "[1 deleted 2 deleted 2 replaced]"; fmt.Sprint(tags) != want {
+		t.Error("case 2 failed: ", tags)
+	}
+}
+
+func Test_ReplaceAllConcurrent(t *testing.T) {
+	lc := NewLRUCache(4, 100, time.Second)
+	gen := func(g int) map[string]interface{} {
+		m := make(map[string]interface{})
+		for i := 0; i < 50; i++ {
+			m[strconv.Itoa(i)] = g
+		}
+		return m
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(2)
+		go func(g int) {
+			lc.ReplaceAll(gen(g))
+			wg.Done()
+		}(g)
+		go func() {
+			for i := 0; i < 50; i++ {
+				lc.Get(strconv.Itoa(i))
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
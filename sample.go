@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"math/rand"
+	"time"
+)
+
+// SampleKeys - get a uniform random sample of at most `n` live keys (reservoir sampling across buckets)
+// for analysis of what dominates the cache without a full dump, buckets are locked one by one
+func (c *Cache) SampleKeys(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	res, seen := make([]string, 0, n), 0
+	for idx := range c.insts {
+		c.lock(idx)
+		now := c.clock.Now()
+		for level, inst := range c.insts[idx] {
+			if inst == nil {
+				continue
+			}
+			inst.foreach(func(k string, v *wrapper) bool {
+				if c.expired(v, now, level) {
+					return true
+				}
+				if level == 1 {
+					if _, dup := c.insts[idx][0].hmap[k]; dup { // counted in level-0
+						return true
+					}
+				}
+				if seen++; len(res) < n {
+					res = append(res, k)
+				} else if j := r.Intn(seen); j < n {
+					res[j] = k
+				}
+				return true
+			})
+		}
+		c.locks[idx].Unlock()
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_SampleKeys(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(4, 100, time.Second).LFU(10).Clock(clk)
+	if len(lc.SampleKeys(3)) != 0 || lc.SampleKeys(0) != nil {
+		t.Error("case 1 failed")
+	}
+	lc.Put("old", "old")
+	clk.Add(2 * time.Second)
+	lc.Put("1", "1")
+	lc.Get("1") // l0 -> l1
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	if s := lc.SampleKeys(3); len(s) != 2 || s[0] == s[1] || s[0] == "old" || s[1] == "old" {
+		t.Error("case 2 failed: ", s)
+	}
+
+	for i := 0; i < 200; i++ {
+		lc.Put(strconv.Itoa(i), i)
+	}
+	cnts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		s := lc.SampleKeys(10)
+		if len(s) != 10 {
+			t.Fatal("case 3 failed")
+		}
+		for _, k := range s {
+			cnts[k]++
+		}
+	}
+	for k, n := range cnts { // expected 100
+		if n < 40 || n > 200 {
+			t.Error("case 4 failed: ", k, n)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// ShadowStats - divergence metrics of shadow read verification
+type ShadowStats struct {
+	Checked  uint64 // sampled hits compared with a fresh load
+	Diverged uint64 // sampled hits that differ from the fresh load
+	Errors   uint64 // sampled hits whose fresh load failed
+	Dropped  uint64 // sampled hits not verified because all workers were busy
+}
+
+// max verifications running in background, samples beyond it are dropped
+const shadowWorkers = 4
+
+// shadow verifies sampled hits against the source of truth
+type shadow struct {
+	stats  ShadowStats // keep first for 64-bit alignment of atomic ops
+	cnt    uint64
+	period uint64
+	loader func(key string) (interface{}, error)
+	equal  func(a, b interface{}) bool
+	sem    chan struct{} // slots of running verifications
+}
+
+// sample is called on every hit, and verifies one of each `period` hits in background
+func (s *shadow) sample(key string, v interface{}) {
+	if atomic.AddUint64(&s.cnt, 1)%s.period != 0 {
+		return
+	}
+	select {
+	case s.sem <- struct{}{}:
+	default: // saturated, e.g. the source of truth is slow
+		atomic.AddUint64(&s.stats.Dropped, 1)
+		return
+	}
+	go func() {
+		defer func() { <-s.sem }()
+		fresh, err := s.loader(key)
+		if err != nil {
+			atomic.AddUint64(&s.stats.Errors, 1)
+			return
+		}
+		atomic.AddUint64(&s.stats.Checked, 1)
+		if !s.equal(v, fresh) {
+			atomic.AddUint64(&s.stats.Diverged, 1)
+		}
+	}()
+}
+
+// Shadow - enable shadow read verification (a correctness canary for invalidation bugs)
+// `rate` is the fraction of hits to verify, e.g. 0.001 verifies one of each 1000 hits
+// `loader` fetches the fresh value from the source of truth, it's called in a separate goroutine,
+// at most `shadowWorkers` of them run at a time, samples beyond are dropped (see `ShadowStats`)
+// `equal` compares cached and fresh values, `reflect.DeepEqual` is used if it's nil
+func (c *Cache) Shadow(rate float64, loader func(key string) (interface{}, error),
+	equal func(a, b interface{}) bool) *Cache {
+	if rate <= 0 || loader == nil {
+		c.shadow = nil
+		return c
+	}
+	period := uint64(1)
+	if rate < 1 {
+		period = uint64(1/rate + 0.5)
+	}
+	if equal == nil {
+		equal = reflect.DeepEqual
+	}
+	c.shadow = &shadow{period: period, loader: loader, equal: equal, sem: make(chan struct{}, shadowWorkers)}
+	return c
+}
+
+// ShadowStats - get divergence metrics of shadow read verification
+func (c *Cache) ShadowStats() (s ShadowStats) {
+	if c.shadow != nil {
+		s.Checked = atomic.LoadUint64(&c.shadow.stats.Checked)
+		s.Diverged = atomic.LoadUint64(&c.shadow.stats.Diverged)
+		s.Errors = atomic.LoadUint64(&c.shadow.stats.Errors)
+		s.Dropped = atomic.LoadUint64(&c.shadow.stats.Dropped)
+	}
+	return
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// wait until verifications in background are done
+func shadowIdle(s *shadow) {
+	for i := 0; i < cap(s.sem); i++ {
+		s.sem <- struct{}{}
+	}
+	for i := 0; i < cap(s.sem); i++ {
+		<-s.sem
+	}
+}
+
+func Test_shadow(t *testing.T) {
+	src := map[string]interface{}{"1": "1", "2": "2"}
+	lc := NewLRUCache(1, 3, time.Second).Shadow(1, func(key string) (interface{}, error) {
+		if v, ok := src[key]; ok {
+			return v, nil
+		}
+		return nil, errors.New("not found")
+	}, nil)
+	lc.Put("1", "1")
+	lc.Put("2", "x") // stale value
+	lc.Put("3", "3") // not in source
+	lc.Get("1")
+	lc.Get("2")
+	lc.Get("3")
+	lc.Get("4") // miss is never verified
+	shadowIdle(lc.shadow)
+	if s := lc.ShadowStats(); s.Checked != 2 || s.Diverged != 1 || s.Errors != 1 {
+		t.Error("case 1 failed: ", s)
+	}
+
+	lc = NewLRUCache(1, 3, time.Second)
+	if s := lc.ShadowStats(); s.Checked != 0 {
+		t.Error("case 2 failed")
+	}
+}
+
+func Test_shadowRate(t *testing.T) {
+	lc := NewLRUCache(1, 3, time.Second).Shadow(0.25, func(key string) (interface{}, error) {
+		return "1", nil
+	}, func(a, b interface{}) bool { return a == b })
+	lc.Put("1", "1")
+	for i := 0; i < 8; i++ {
+		lc.Get("1")
+	}
+	shadowIdle(lc.shadow)
+	if s := lc.ShadowStats(); s.Checked != 2 || s.Diverged != 0 {
+		t.Error("case 1 failed: ", s)
+	}
+}
+
+func Test_shadowSaturated(t *testing.T) {
+	release := make(chan struct{})
+	lc := NewLRUCache(1, 3, time.Second).Shadow(1, func(key string) (interface{}, error) {
+		<-release
+		return "1", nil
+	}, nil)
+	lc.Put("1", "1")
+	for i := 0; i < shadowWorkers+2; i++ {
+		lc.Get("1")
+	}
+	if s := lc.ShadowStats(); s.Dropped != 2 || s.Checked != 0 {
+		t.Error("case 1 failed: ", s)
+	}
+	close(release)
+	shadowIdle(lc.shadow)
+	if s := lc.ShadowStats(); s.Checked != shadowWorkers || s.Diverged != 0 {
+		t.Error("case 2 failed: ", s)
+	}
+}
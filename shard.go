@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// counters of a bucket, updated with the lock of the bucket held
+type counters struct {
+	puts         uint64
+	hits         uint64
+	misses       uint64
+	evictions    uint64
+	expired      uint64 // reads that found the item expired
+	waits        uint64
+	waitNs       int64
+	installs     [sourceCnt]uint64 // puts by each `Source`
+	archives     uint64
+	archiveDrops uint64
+}
+
+// ShardStats - occupancy and counters of a bucket
+type ShardStats struct {
+	Len          int   // count of items in level-0
+	Cap          int   // capacity of level-0, 0 if it's bounded by budget
+	LFULen       int   // count of items in level-1, 0 if lfu is not enabled
+	LFUCap       int   // capacity of level-1, 0 if lfu is not enabled or it's bounded by budget
+	Cost         int64 // total cost of items in level-0, see `NewLRUCacheWithBudget`
+	Budget       int64 // budget of level-0, 0 if it's bounded by count of items
+	LFUCost      int64 // total cost of items in level-1
+	LFUBudget    int64 // budget of level-1
+	Puts         uint64
+	Hits         uint64
+	Misses       uint64            // including expired reads
+	Expired      uint64            // reads that found the item expired
+	Evictions    uint64            // items evicted because the bucket (or its level) was full
+	LockWaits    uint64            // times the lock was contended
+	LockWait     time.Duration     // total time waited for the lock
+	Installs     [sourceCnt]uint64 // items put by each `Source`, indexed by it
+	Archived     uint64            // evicted items handed to the archiver, see `Archive`
+	ArchiveDrops uint64            // evicted items dropped because the queue of the archiver was full
+}
+
+// Stats - counters and occupancy summed over buckets, and the ones of each bucket
+type Stats struct {
+	ShardStats
+	Shards       []ShardStats
+	DistinctKeys uint64 // approximate count of distinct keys requested, 0 if `CountDistinct` is not enabled
+	SweepSkips   uint64 // buckets the janitor skipped since their locks were held, see `JanitorBudget`
+	EvictDrops   uint64 // evictions dropped because the queue of `OnEvictAsync` was full
+}
+
+// Shards - count of buckets
+func (c *Cache) Shards() int {
+	return len(c.insts)
+}
+
+// ShardStats - get occupancy and counters of the bucket `i` in [0, Shards())
+func (c *Cache) ShardStats(i int) (s ShardStats) {
+	c.locks[i].Lock()
+	s.Len, s.Cap = c.insts[i][0].length(), c.insts[i][0].capacity()
+	s.Cost, s.Budget = c.insts[i][0].used, c.insts[i][0].budget
+	if c.insts[i][1] != nil {
+		s.LFULen, s.LFUCap = c.insts[i][1].length(), c.insts[i][1].capacity()
+		s.LFUCost, s.LFUBudget = c.insts[i][1].used, c.insts[i][1].budget
+	}
+	cnt := c.locks[i].cnts
+	s.Puts, s.Hits, s.Misses, s.Expired = cnt.puts, cnt.hits, cnt.misses, cnt.expired
+	s.Evictions, s.LockWaits, s.LockWait = cnt.evictions, cnt.waits, time.Duration(cnt.waitNs)
+	s.Installs = cnt.installs
+	s.Archived, s.ArchiveDrops = cnt.archives, cnt.archiveDrops
+	c.locks[i].Unlock()
+	return
+}
+
+// Stats - get counters and occupancy of all buckets, e.g. to tune count and capacity of buckets by hit ratio and evictions,
+// the totals include counters carried over from former processes by `AddCarryover`
+// each bucket is locked in turn, so it's cheap for the hot path but not a point-in-time view of the whole cache
+func (c *Cache) Stats() (s Stats) {
+	s.Shards = make([]ShardStats, len(c.insts))
+	for i := range s.Shards {
+		s.Shards[i] = c.ShardStats(i)
+		s.add(&s.Shards[i])
+	}
+	c.carryMu.Lock()
+	s.add(&c.carried)
+	c.carryMu.Unlock()
+	s.DistinctKeys = c.DistinctKeys()
+	s.SweepSkips = atomic.LoadUint64(&c.sweepSkips)
+	s.EvictDrops = atomic.LoadUint64(&c.evictDrops)
+	return
+}
+
+// sum `ss` into s
+func (s *ShardStats) add(ss *ShardStats) {
+	s.Len, s.Cap, s.LFULen, s.LFUCap = s.Len+ss.Len, s.Cap+ss.Cap, s.LFULen+ss.LFULen, s.LFUCap+ss.LFUCap
+	s.Cost, s.Budget, s.LFUCost, s.LFUBudget = s.Cost+ss.Cost, s.Budget+ss.Budget, s.LFUCost+ss.LFUCost, s.LFUBudget+ss.LFUBudget
+	s.Puts, s.Hits, s.Misses, s.Expired = s.Puts+ss.Puts, s.Hits+ss.Hits, s.Misses+ss.Misses, s.Expired+ss.Expired
+	s.Evictions, s.LockWaits, s.LockWait = s.Evictions+ss.Evictions, s.LockWaits+ss.LockWaits, s.LockWait+ss.LockWait
+	s.Archived, s.ArchiveDrops = s.Archived+ss.Archived, s.ArchiveDrops+ss.ArchiveDrops
+	for i := range s.Installs {
+		s.Installs[i] += ss.Installs[i]
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_ShardStats(t *testing.T) {
+	lc := NewLRUCache(1, 2, time.Second).LFU(1)
+	if lc.Shards() != 1 {
+		t.Error("case 1 failed")
+	}
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Put("3", "3") // evicts "1"
+	lc.Get("2")      // l0 -> l1
+	lc.Get("3")      // l0 -> l1, evicts "2"
+	lc.Put("4", "4")
+	s := lc.ShardStats(0)
+	if s.Len != 1 || s.Cap != 2 || s.LFULen != 1 || s.LFUCap != 1 || s.Evictions != 2 {
+		t.Error("case 2 failed: ", s)
+	}
+	if s.LockWaits != 0 || s.LockWait != 0 {
+		t.Error("case 3 failed: ", s)
+	}
+
+	lc.locks[0].Lock()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		lc.Put("5", "5")
+		wg.Done()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	lc.locks[0].Unlock()
+	wg.Wait()
+	if s = lc.ShardStats(0); s.LockWaits != 1 || s.LockWait < 5*time.Millisecond {
+		t.Error("case 4 failed: ", s)
+	}
+
+	if s = NewLRUCache(4, 2, time.Second).ShardStats(3); s.Cap != 2 || s.LFUCap != 0 {
+		t.Error("case 5 failed: ", s)
+	}
+}
+
+func Test_Stats(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(2, 3, time.Second).LFU(1).Clock(clk).CountDistinct()
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Put("3", "3")
+	lc.Get("1")
+	lc.Get("4")
+	clk.Add(2 * time.Second)
+	lc.Get("2")
+	lc.Get("1")
+	s := lc.Stats()
+	if len(s.Shards) != 2 || s.Cap != 6 || s.LFUCap != 2 || s.Len+s.LFULen != 2 {
+		t.Error("case 1 failed: ", s)
+	}
+	if s.Puts != 3 || s.Hits != 1 || s.Misses != 3 || s.Expired != 2 || s.DistinctKeys != 3 {
+		t.Error("case 2 failed: ", s)
+	}
+	if s.Shards[0].Puts+s.Shards[1].Puts != 3 || s.Shards[0].Hits+s.Shards[1].Hits != 1 {
+		t.Error("case 3 failed: ", s)
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// HitRatioAlert - hit ratio has been below the target for consecutive windows
+type HitRatioAlert struct {
+	Ratio   float64 // hit ratio of the last window
+	Target  float64
+	Windows int // count of consecutive windows below the target
+	Hits    uint64
+	Misses  uint64 // of the last window
+}
+
+// state of `WatchHitRatio`
+type slo struct {
+	target float64
+	n      int
+	prev   [2]uint64 // total hits and misses at the end of the last window
+	below  int
+}
+
+// close a window with total hits and misses by now, returns the alert when it's the n-th consecutive window below target,
+// windows without gets are skipped
+func (s *slo) window(hits, misses uint64) (a HitRatioAlert, ok bool) {
+	h, m := hits-s.prev[0], misses-s.prev[1]
+	s.prev = [2]uint64{hits, misses}
+	if h+m == 0 {
+		return
+	}
+	ratio := float64(h) / float64(h+m)
+	if ratio >= s.target {
+		s.below = 0
+		return
+	}
+	if s.below++; s.below != s.n {
+		return
+	}
+	return HitRatioAlert{ratio, s.target, s.below, h, m}, true
+}
+
+// total hits and misses of all buckets
+func (c *Cache) hitsMisses() (hits, misses uint64) {
+	for idx := range c.insts {
+		c.locks[idx].Lock()
+		hits, misses = hits+c.locks[idx].cnts.hits, misses+c.locks[idx].cnts.misses
+		c.locks[idx].Unlock()
+	}
+	return
+}
+
+// WatchHitRatio - start a watchdog that computes the hit ratio of each `window`, and calls `fn` once the ratio
+// is below `target` for `n` consecutive windows (again after it recovers and drops again), as an early signal of
+// invalidation bugs or capacity shortfalls, windows without gets are skipped
+// call the returned function to stop it, more calls are no-op
+func (c *Cache) WatchHitRatio(window time.Duration, target float64, n int, fn func(HitRatioAlert)) (stop func()) {
+	if n <= 0 {
+		n = 1
+	}
+	var once sync.Once
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(window)
+		s := &slo{target: target, n: n}
+		s.prev[0], s.prev[1] = c.hitsMisses() // start counting from now
+		for {
+			select {
+			case <-t.C:
+				if a, ok := s.window(c.hitsMisses()); ok {
+					fn(a)
+				}
+			case <-done:
+				t.Stop()
+				return
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_slo(t *testing.T) {
+	s := &slo{target: 0.8, n: 2}
+	if _, ok := s.window(9, 1); ok { // 0.9
+		t.Error("case 1 failed")
+	}
+	if _, ok := s.window(14, 6); ok { // 0.5, 1st
+		t.Error("case 2 failed")
+	}
+	if _, ok := s.window(14, 6); ok { // no gets, skipped
+		t.Error("case 3 failed")
+	}
+	a, ok := s.window(17, 13) // 0.3, 2nd
+	if !ok || a.Ratio != 0.3 || a.Windows != 2 || a.Hits != 3 || a.Misses != 7 || a.Target != 0.8 {
+		t.Error("case 4 failed: ", a)
+	}
+	if _, ok := s.window(17, 23); ok { // 0, 3rd, fired already
+		t.Error("case 5 failed")
+	}
+	s.window(27, 23) // recovered
+	s.window(27, 33)
+	if _, ok := s.window(27, 43); !ok {
+		t.Error("case 6 failed")
+	}
+}
+
+func Test_WatchHitRatio(t *testing.T) {
+	lc := NewLRUCache(4, 100, time.Second)
+	lc.Get("before") // not counted
+	ch := make(chan HitRatioAlert, 10)
+	stop := lc.WatchHitRatio(10*time.Millisecond, 0.5, 1, func(a HitRatioAlert) { ch <- a })
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 100; i++ {
+		lc.Get("none")
+	}
+	select {
+	case a := <-ch:
+		if a.Ratio != 0 || a.Misses == 0 || a.Misses > 100 {
+			t.Error("case 1 failed: ", a)
+		}
+	case <-time.After(time.Second):
+		t.Error("case 2 failed")
+	}
+	stop()
+	stop() // no-op
+}
@@ -0,0 +1,115 @@
+package cache
+
+import "time"
+
+// a soft-deleted item
+type trashed struct {
+	w     *wrapper
+	level int
+	at    int64 // when it's soft-deleted, by the clock of the cache
+}
+
+// SoftDelete - keep items removed by `SoftDel` for `window`, during which `Restore` can bring them back,
+// e.g. for undo of moderation where recomputing the value is expensive
+func (c *Cache) SoftDelete(window time.Duration) *Cache {
+	c.trashWindow = window
+	c.trash = nil
+	if window > 0 {
+		c.trash = make([]side[trashed], len(c.insts))
+	}
+	return c
+}
+
+// SoftDel - hide the item of key from `Get` but keep it for the window of `SoftDelete`, report whether it's live,
+// it leaves the cache (`OnEvict` etc. are called with reason `Deleted`) after the window passes, at the next pass of
+// `Janitor` over its bucket, `Restore` of it, or each time the trash of its bucket grows by 1024 items,
+// or once it's replaced by a `Put` (with reason `Replaced`) or `Del`, it's the same as `Del` if `SoftDelete` is not enabled
+func (c *Cache) SoftDel(key string) bool {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	if c.freezes[idx] != nil {
+		c.thawed(idx) // deferred writes may change the item
+	}
+	w, level := c.peek(key, idx)
+	if c.trash == nil {
+		c.remove(key, idx)
+		return w != nil
+	}
+	if w == nil {
+		return false
+	}
+	from := Absent
+	if c.onState != nil {
+		from = c.stateOf(key, idx)
+	}
+	for l, inst := range c.insts[idx] {
+		if inst == nil {
+			continue
+		}
+		if v, ok := inst.del(key); ok && l != level {
+			c.drop(key, idx, v, Deleted) // stale one of the other level
+		}
+	}
+	if w.watch != nil { // it's hidden, watchers see it leaves
+		w.watch.close()
+		w.watch = nil
+	}
+	now := c.clock.Now()
+	c.trash[idx].set(key, trashed{w, level, now}, func(k string, t trashed) bool { return c.outdated(k, idx, t, now) })
+	if c.onState != nil {
+		c.transit(key, from, Hidden)
+	}
+	return true
+}
+
+// drop soft-deleted items of bucket `idx` out of the window, lock of the bucket must be held
+func (c *Cache) purgeTrash(idx int) {
+	now := c.clock.Now()
+	c.trash[idx].prune(func(k string, t trashed) bool { return c.outdated(k, idx, t, now) })
+}
+
+// whether the soft-deleted item is out of the window, then it's dropped
+func (c *Cache) outdated(key string, idx int, t trashed, now int64) bool {
+	if now-t.at <= int64(c.trashWindow) {
+		return false
+	}
+	c.dropFrom(key, idx, t.w, Deleted, Hidden)
+	return true
+}
+
+// Restore - bring back the item soft-deleted by `SoftDel` within the window, report whether it's restored,
+// it's restored as it was (but with `Source` of `FromRestore`), so the time in trash counts towards its expiration,
+// it's put as the other puts are, so it's rejected by the tombstone of key (see `Tombstone`)
+func (c *Cache) Restore(key string) bool {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	if c.freezes[idx] != nil {
+		c.thawed(idx)
+	}
+	if c.trash == nil {
+		return false
+	}
+	t, ok := c.trash[idx].m[key]
+	if !ok {
+		return false
+	}
+	if c.clock.Now()-t.at > int64(c.trashWindow) || c.tombs != nil && c.buried(key, idx, t.w.ver) {
+		c.discard(key, idx, Deleted)
+		return false
+	}
+	if c.insts[idx][t.level] == nil {
+		t.level = 0
+	}
+	t.w.src = FromRestore
+	return c.insert(key, idx, t.level, t.w) // it's taken out of the trash there
+}
+
+// drop the soft-deleted item of key if any, lock of the bucket must be held
+func (c *Cache) discard(key string, idx int, reason EvictReason) {
+	if t, ok := c.trash[idx].m[key]; ok {
+		c.trash[idx].del(key)
+		c.dropFrom(key, idx, t.w, reason, Hidden)
+	}
+}
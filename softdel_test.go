@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_SoftDel(t *testing.T) {
+	clk := &fakeClock{}
+	var evs []string
+	lc := NewLRUCache(1, 10, time.Minute).Clock(clk).SoftDelete(time.Second).
+		OnEvict(func(key string, val interface{}, reason EvictReason) { evs = append(evs, key+":"+reason.String()) })
+	lc.Put("1", 1)
+	if !lc.SoftDel("1") || lc.SoftDel("1") || lc.SoftDel("none") {
+		t.Error("case 1 failed")
+	}
+	if _, ok := lc.Get("1"); ok || len(evs) != 0 {
+		t.Error("case 2 failed")
+	}
+	if !lc.Restore("1") || lc.Restore("1") || lc.Restore("none") {
+		t.Error("case 3 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != 1 {
+		t.Error("case 4 failed")
+	}
+
+	// out of window
+	lc.SoftDel("1")
+	clk.Add(time.Second + 1)
+	if lc.Restore("1") || len(evs) != 1 || evs[0] != "1:deleted" {
+		t.Error("case 5 failed: ", evs)
+	}
+
+	// replaced or deleted meanwhile
+	lc.Put("2", 2)
+	lc.SoftDel("2")
+	lc.Put("2", 22)
+	if lc.Restore("2") || len(evs) != 2 || evs[1] != "2:replaced" {
+		t.Error("case 6 failed: ", evs)
+	}
+	if v, _ := lc.Get("2"); v != 22 {
+		t.Error("case 7 failed")
+	}
+	lc.SoftDel("2")
+	lc.Del("2")
+	if lc.Restore("2") || len(evs) != 3 || evs[2] != "2:deleted" {
+		t.Error("case 8 failed: ", evs)
+	}
+
+	lc.Put("3", 3)
+	lc.SoftDel("3")
+	lc.Clear()
+	if lc.Restore("3") || len(evs) != 4 || evs[3] != "3:deleted" {
+		t.Error("case 9 failed: ", evs)
+	}
+
+	// purged as it grows
+	for i := 0; i < purgeEvery; i++ {
+		lc.Put(strconv.Itoa(i), i)
+		lc.SoftDel(strconv.Itoa(i))
+		if i == 0 {
+			clk.Add(2 * time.Second)
+		}
+	}
+	if len(lc.trash[0].m) != purgeEvery-1 || len(evs) != 5 || evs[4] != "0:deleted" {
+		t.Error("case 10 failed: ", len(lc.trash[0].m), evs[4:])
+	}
+}
+
+func Test_SoftDelLFU(t *testing.T) {
+	lc := NewLRUCache(1, 10, 0).LFU(10).SoftDelete(time.Second)
+	lc.Put("1", 1)
+	lc.Get("1") // l0 -> l1
+	lc.Put("1", 11)
+	lc.SoftDel("1")
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 1 failed")
+	}
+	lc.Restore("1")
+	if v, ok := lc.Get("1"); !ok || v != 11 {
+		t.Error("case 2 failed")
+	}
+
+	lc = NewLRUCache(1, 10, 0)
+	lc.Put("1", 1)
+	if !lc.SoftDel("1") || lc.Restore("1") {
+		t.Error("case 3 failed")
+	}
+}
+
+func Test_SoftDelHooks(t *testing.T) {
+	clk := &fakeClock{}
+	var got []string
+	lc := NewLRUCache(1, 10, time.Minute).Clock(clk).SoftDelete(time.Second).Tombstone(time.Second).
+		OnState(func(key string, from, to State) { got = append(got, fmt.Sprint(key, ":", from, "->", to)) }).
+		KeyspaceEvents("*", func(e KeyspaceEvent) { got = append(got, e.Key+":"+e.Event) })
+	check := func(c string, want ...string) {
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Error("case "+c+" failed", got)
+		}
+		got = nil
+	}
+	lc.Put("1", 1)
+	check("1", "1:absent->ready", "1:set")
+	lc.SoftDel("1")
+	check("2", "1:ready->hidden")
+	if lc.StateOf("1") != Hidden || Hidden.String() != "hidden" {
+		t.Error("case 3 failed")
+	}
+	lc.Restore("1")
+	check("4", "1:hidden->ready", "1:set")
+	if src, _ := lc.SourceOf("1"); src != FromRestore || src.String() != "restore" {
+		t.Error("case 5 failed")
+	}
+	if s := lc.Stats(); s.Puts != 2 || s.Installs[FromRestore] != 1 {
+		t.Error("case 6 failed: ", s.Puts, s.Installs)
+	}
+	lc.SoftDel("1")
+	clk.Add(2 * time.Second)
+	lc.Restore("1")
+	check("7", "1:ready->hidden", "1:hidden->evicting", "1:del")
+
+	// the tombstone is honored
+	lc.Del("2")
+	lc.ReplaceAll(map[string]interface{}{"2": 2})
+	lc.SoftDel("2")
+	got = nil
+	if lc.Restore("2") || lc.StateOf("2") != Absent {
+		t.Error("case 8 failed")
+	}
+	check("9", "2:hidden->evicting", "2:del")
+}
+
+func Test_SoftDelJanitor(t *testing.T) {
+	evs := make(chan string, 10)
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 10, 0).Clock(clk).SoftDelete(time.Second).
+		OnEvict(func(key string, val interface{}, reason EvictReason) { evs <- key + ":" + reason.String() })
+	defer lc.Close()
+	lc.Put("1", 1)
+	lc.Put("2", 2)
+	lc.SoftDel("1")
+	clk.Add(2 * time.Second)
+	lc.SoftDel("2")
+	lc.Janitor(time.Millisecond)
+	select {
+	case e := <-evs:
+		if e != "1:deleted" {
+			t.Error("case 1 failed: ", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("case 2 failed")
+	}
+	lc.Close()
+	if !lc.Restore("2") {
+		t.Error("case 3 failed")
+	}
+}
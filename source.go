@@ -0,0 +1,46 @@
+package cache
+
+// Source - the path that installed a item, to find out which one put stale data
+type Source uint8
+
+const (
+	FromPut      Source = iota // `Put` and its variants by caller
+	FromLoader                 // loaded by `GetOrLoadWith` (and `WithTTL`)
+	FromRefresh                // reloaded in background by `LFURefresh`
+	FromSnapshot               // restored by `Import` (e.g. `persist.Load`)
+	FromReplace                // installed by `ReplaceAll`
+	FromRestore                // brought back by `Restore`
+	FromPrefetch               // loaded in background by `Prefetch`
+	sourceCnt
+)
+
+func (s Source) String() string {
+	switch s {
+	case FromPut:
+		return "put"
+	case FromLoader:
+		return "loader"
+	case FromRefresh:
+		return "refresh"
+	case FromSnapshot:
+		return "snapshot"
+	case FromReplace:
+		return "replace"
+	case FromRestore:
+		return "restore"
+	case FromPrefetch:
+		return "prefetch"
+	}
+	return "unknown"
+}
+
+// SourceOf - get the path that installed the live item of key
+func (c *Cache) SourceOf(key string) (Source, bool) {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	if w, _ := c.peek(key, idx); w != nil {
+		return w.src, true
+	}
+	return 0, false
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_SourceOf(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 10, time.Second).LFU(10).Clock(clk)
+	if _, ok := lc.SourceOf("1"); ok {
+		t.Error("case 1 failed")
+	}
+	lc.Put("1", 1)
+	lc.GetOrLoadWith("2", func(key string) (interface{}, error) { return 2, nil })
+	lc.GetOrLoadWithTTL("3", time.Minute, func(key string) (interface{}, error) { return 3, nil })
+	if s, ok := lc.SourceOf("1"); !ok || s != FromPut {
+		t.Error("case 2 failed")
+	}
+	if s, _ := lc.SourceOf("2"); s != FromLoader {
+		t.Error("case 3 failed")
+	}
+	if s, _ := lc.SourceOf("3"); s != FromLoader {
+		t.Error("case 4 failed")
+	}
+	lc.Get("2") // l0 -> l1, keeps the source
+	lc.Rename("2", "22", false)
+	if s, _ := lc.SourceOf("22"); s != FromLoader {
+		t.Error("case 5 failed")
+	}
+
+	lc2 := NewLRUCache(1, 10, time.Second)
+	lc.Export(func(r Record) bool {
+		return lc2.Import(r, 0)
+	})
+	if s, _ := lc2.SourceOf("1"); s != FromSnapshot {
+		t.Error("case 6 failed")
+	}
+	lc2.ReplaceAll(map[string]interface{}{"1": 1})
+	if s, _ := lc2.SourceOf("1"); s != FromReplace {
+		t.Error("case 7 failed")
+	}
+	if s := lc2.Stats(); s.Installs[FromSnapshot] != 3 || s.Installs[FromReplace] != 1 {
+		t.Error("case 8 failed: ", s.Installs)
+	}
+
+	lc.LFURefresh(func(key string) (interface{}, error) { return 222, nil })
+	clk.Add(2 * time.Second)
+	lc.Get("22")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	lc.Await(ctx, "22")
+	if s, _ := lc.SourceOf("22"); s != FromRefresh {
+		t.Error("case 9 failed")
+	}
+	lc.Range(func(e EntryView) bool {
+		if e.Key == "22" && e.Source != FromRefresh {
+			t.Error("case 10 failed")
+		}
+		return true
+	})
+	if s := lc.Stats(); s.Installs[FromPut] != 1 || s.Installs[FromLoader] != 2 || s.Installs[FromRefresh] != 1 {
+		t.Error("case 11 failed: ", s.Installs)
+	}
+	if FromRefresh.String() != "refresh" || Source(99).String() != "unknown" {
+		t.Error("case 12 failed")
+	}
+}
@@ -0,0 +1,83 @@
+package cache
+
+// State - lifecycle state of a key
+type State int
+
+const (
+	Absent   State = iota
+	Filling        // being loaded by `GetOrLoadWith`
+	Ready          // live
+	Stale          // expired but not removed yet
+	Evicting       // leaving the cache (evicted, expired, deleted, or renamed), only seen by the hook
+	Hidden         // soft-deleted by `SoftDel`, until it's restored, replaced or leaves
+)
+
+func (s State) String() string {
+	switch s {
+	case Absent:
+		return "absent"
+	case Filling:
+		return "filling"
+	case Ready:
+		return "ready"
+	case Stale:
+		return "stale"
+	case Evicting:
+		return "evicting"
+	case Hidden:
+		return "hidden"
+	}
+	return "unknown"
+}
+
+// state of key, lock of the bucket must be held
+func (c *Cache) stateOf(key string, idx int) State {
+	if _, ok := c.calls[idx][key]; ok {
+		return Filling
+	}
+	s, now := Absent, c.clock.Now()
+	for level, inst := range c.insts[idx] {
+		if inst == nil {
+			continue
+		}
+		if e, ok := inst.hmap[key]; ok {
+			if !c.expired(e.v, now, level) {
+				return Ready
+			}
+			s = Stale
+		}
+	}
+	if s == Absent && c.trash != nil {
+		if _, ok := c.trash[idx].m[key]; ok {
+			return Hidden
+		}
+	}
+	return s
+}
+
+// call the hook if the state changes, lock of the bucket must be held
+func (c *Cache) transit(key string, from, to State) {
+	if from != to {
+		c.onState(key, from, to)
+	}
+}
+
+// StateOf - get the lifecycle state of key, e.g. to render cache state in tooling,
+// or to wait for a `Filling` key instead of fetching it again
+func (c *Cache) StateOf(key string) State {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	s := c.stateOf(key, idx)
+	c.locks[idx].Unlock()
+	return s
+}
+
+// OnState - set a hook called on transitions of lifecycle state, i.e.
+// absent/stale -> filling (a load starts), filling -> absent/stale (the load fails), any -> ready (a item is put),
+// ready/stale -> evicting (a item leaves), ready/stale -> hidden (`SoftDel`), hidden -> ready (`Restore` or a put),
+// hidden -> evicting (a soft-deleted item leaves), it never reports ready -> stale as expiration is noticed lazily
+// it's called with the lock of the bucket held, it must be fast and must not call back into the cache
+func (c *Cache) OnState(fn func(key string, from, to State)) *Cache {
+	c.onState = fn
+	return c
+}
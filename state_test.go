@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_OnState(t *testing.T) {
+	var got []string
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 2, time.Second).Clock(clk).OnState(func(key string, from, to State) {
+		got = append(got, fmt.Sprint(key, ":", from, "->", to))
+	})
+	check := func(c string, want ...string) {
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Error("case "+c+" failed", got)
+		}
+		got = nil
+	}
+	lc.GetOrLoadWith("1", func(key string) (interface{}, error) {
+		if lc.StateOf("1") != Filling { // no deadlock, the lock isn't held while loading
+			t.Error("case 1 failed")
+		}
+		return "1", nil
+	})
+	check("2", "1:absent->filling", "1:filling->ready")
+	if lc.StateOf("1") != Ready || lc.StateOf("2") != Absent {
+		t.Error("case 3 failed")
+	}
+	lc.Put("1", "2")
+	check("4")
+	clk.Add(2 * time.Second)
+	if lc.StateOf("1") != Stale {
+		t.Error("case 5 failed")
+	}
+	lc.GetOrLoadWith("1", func(key string) (interface{}, error) { return nil, errors.New("load") })
+	check("6", "1:stale->filling", "1:filling->stale")
+	lc.Put("1", "3")
+	check("7", "1:stale->ready")
+	lc.Put("2", "2")
+	lc.Put("3", "3")
+	check("8", "2:absent->ready", "1:ready->evicting", "3:absent->ready")
+	lc.Rename("3", "4", false)
+	check("9", "3:ready->evicting", "4:absent->ready")
+	lc.Del("4")
+	check("10", "4:ready->evicting")
+	lc.ReplaceAll(map[string]interface{}{"2": "4", "5": "5"})
+	check("11", "5:absent->ready")
+	lc.Get("5") // order of the replaced entries is random
+	lc.PutIfAbsent("1", "1")
+	check("12", "2:ready->evicting", "1:absent->ready")
+	if Evicting.String() != "evicting" || State(-1).String() != "unknown" {
+		t.Error("case 13 failed")
+	}
+}
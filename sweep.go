@@ -0,0 +1,25 @@
+package cache
+
+// Sweep - enable amortized active expiration without any background goroutine (like redis's activeexpire)
+// each `Put`/`Get` walks at most `n` items of its bucket from where the last step stopped, and evicts the expired ones
+// it keeps expired items from holding memory until they rotate out, while the package stays goroutine-free
+func (c *Cache) Sweep(n int) *Cache {
+	c.sweep = n
+	return c
+}
+
+// a sweep step of bucket `idx` walks at most `n` items of each level, lock of the bucket must be held
+func (c *Cache) step(idx, n int) {
+	now := c.clock.Now()
+	for level, inst := range c.insts[idx] {
+		if inst != nil {
+			inst.sweep(n, func(k string, v *wrapper) bool {
+				if c.expired(v, now, level) {
+					c.drop(k, idx, v, Expired)
+					return true
+				}
+				return false
+			})
+		}
+	}
+}
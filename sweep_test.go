@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_sweep(t *testing.T) {
+	c := create[string, interface{}](5)
+	c.put("1", 1)
+	c.put("2", 2)
+	c.put("3", 3)
+	c.put("4", 4)
+	odd := func(_ string, v interface{}) bool { return v.(int)%2 == 1 }
+	if c.sweep(1, odd) != 1 || c.length() != 3 { // "1" at tail
+		t.Error("case 1.1 failed")
+	}
+	if c.sweep(1, odd) != 0 || c.length() != 3 { // "2"
+		t.Error("case 1.2 failed")
+	}
+	c.get("3") // refresh the node under cursor
+	if c.sweep(10, odd) != 1 || c.length() != 2 {
+		t.Error("case 1.3 failed")
+	}
+	for i := c.head; i != nil; i = i.n {
+		if odd(i.k, i.v) {
+			t.Error("case 1.4 failed: ", i.k)
+		}
+	}
+	c.put("5", 5)
+	c.put("6", 6)
+	c.put("7", 7)
+	c.put("8", 8) // reuse tail
+	if c.sweep(10, odd) != 2 || c.length() != 3 {
+		t.Error("case 1.5 failed")
+	}
+	if c.sweep(10, func(string, interface{}) bool { return true }) != 3 || c.length() != 0 || c.head != nil || c.tail != nil {
+		t.Error("case 1.6 failed")
+	}
+}
+
+func Test_Sweep(t *testing.T) {
+	lc := NewLRUCache(1, 10, 100*time.Millisecond).Sweep(2)
+	for _, k := range []string{"1", "2", "3", "4", "5"} {
+		lc.Put(k, k)
+	}
+	time.Sleep(100 * time.Millisecond)
+	lc.Put("6", "6") // sweeps "1", "2"
+	if lc.insts[0][0].length() != 4 {
+		t.Error("case 1 failed")
+	}
+	lc.Get("6") // sweeps "3", "4"
+	lc.Get("6") // sweeps "5", keeps "6"
+	if lc.insts[0][0].length() != 1 {
+		t.Error("case 2 failed")
+	}
+	if v, ok := lc.Get("6"); !ok || v != "6" {
+		t.Error("case 3 failed")
+	}
+
+	lc = NewLRUCache(1, 10, 100*time.Millisecond).LFU(10).Sweep(10)
+	lc.Put("1", "1")
+	lc.Put("2", "2")
+	lc.Get("1") // l0 -> l1
+	time.Sleep(100 * time.Millisecond)
+	lc.Put("3", "3")
+	if lc.insts[0][0].length() != 1 || lc.insts[0][1].length() != 0 {
+		t.Error("case 4 failed")
+	}
+}
@@ -0,0 +1,41 @@
+package cache
+
+import "context"
+
+type tagKey struct{}
+
+// WithTag - attach an opaque `tag` (e.g. the trace id of a request) to ctx, so audit trails can tie changes of the cache
+// back to the request that made them: writes made by `PutCtx`, `DelCtx` and `GetOrLoadCtx` with ctx carry the tag
+// to the loader (by ctx), `KeyspaceEvent`, `TraceRecord` and `OnEvictTag` of the changes they cause (including
+// items replaced or evicted by capacity to make room), changes by other paths carry nil
+func WithTag(ctx context.Context, tag interface{}) context.Context {
+	return context.WithValue(ctx, tagKey{}, tag)
+}
+
+// TagOf - get the tag attached to ctx by `WithTag`, nil if none
+func TagOf(ctx context.Context) interface{} {
+	return ctx.Value(tagKey{})
+}
+
+// PutCtx - the same as `Put`, with the tag of ctx (see `WithTag`)
+func (c *Cache) PutCtx(ctx context.Context, key string, val interface{}) {
+	c.putTagged(key, val, 0, 0, FromPut, TagOf(ctx))
+}
+
+// DelCtx - the same as `Del`, with the tag of ctx (see `WithTag`)
+func (c *Cache) DelCtx(ctx context.Context, key string) {
+	c.del(key, TagOf(ctx))
+}
+
+// GetOrLoadCtx - the same as `GetOrLoadWith`, but `loader` is given ctx of the call that triggers the load,
+// and the loaded item is put with the tag of it (see `WithTag`), concurrent misses wait for that call
+func (c *Cache) GetOrLoadCtx(ctx context.Context, key string,
+	loader func(ctx context.Context, key string) (interface{}, error)) (interface{}, error) {
+	return c.load(key, nil, func(key string) (interface{}, error) { return loader(ctx, key) }, TagOf(ctx))
+}
+
+// OnEvictTag - the same as `OnEvict`, but `tag` is of the write that caused the item to leave (see `WithTag`)
+func (c *Cache) OnEvictTag(fn func(key string, val interface{}, reason EvictReason, tag interface{})) *Cache {
+	c.onEvictTag = fn
+	return c
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_WithTag(t *testing.T) {
+	var evs, evicts []string
+	lc := NewLRUCache(1, 2, time.Second).Trace("*", 16).
+		KeyspaceEvents("*", func(e KeyspaceEvent) { evs = append(evs, fmt.Sprint(e.Event, " ", e.Key, " ", e.Tag)) }).
+		OnEvictTag(func(key string, val interface{}, reason EvictReason, tag interface{}) {
+			evicts = append(evicts, fmt.Sprint(key, " ", reason, " ", tag))
+		})
+	ctx := WithTag(context.Background(), "req-1")
+	if TagOf(ctx) != "req-1" || TagOf(context.Background()) != nil {
+		t.Error("case 1 failed")
+	}
+
+	lc.Put("1", 1)
+	lc.PutCtx(ctx, "2", 2)
+	lc.PutCtx(WithTag(ctx, "req-2"), "3", 3) // evicts "1"
+	lc.DelCtx(ctx, "2")
+	lc.Del("3")
+	if fmt.Sprint(evs) != "[set 1 <nil> set 2 req-1 evicted 1 req-2 set 3 req-2 del 2 req-1 del 3 <nil>]" {
+		t.Error("case 2 failed: ", evs)
+	}
+	if fmt.Sprint(evicts) != "[1 evicted req-2 2 deleted req-1 3 deleted <nil>]" {
+		t.Error("case 3 failed: ", evicts)
+	}
+	if log := lc.TraceLog(); len(log) != 6 || log[1].Tag != "req-1" || log[5].Tag != nil {
+		t.Error("case 4 failed: ", log)
+	}
+
+	// the loader is given ctx of the call
+	evs = nil
+	v, err := lc.GetOrLoadCtx(ctx, "4", func(ctx context.Context, key string) (interface{}, error) {
+		return TagOf(ctx), nil
+	})
+	if v != "req-1" || err != nil || fmt.Sprint(evs) != "[set 4 req-1]" {
+		t.Error("case 5 failed: ", v, evs)
+	}
+
+	// deferred writes keep their tags
+	evs = nil
+	lc.FreezeShard(0)
+	lc.PutCtx(ctx, "5", 5)
+	lc.Thaw(0)
+	if fmt.Sprint(evs) != "[set 5 req-1]" {
+		t.Error("case 6 failed: ", evs)
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+// Token - a write returned by `PutAndGetToken`, the zero value stands for no write
+type Token struct {
+	idx int
+	f   *freeze // deferring the write, nil if it's applied
+}
+
+// PutAndGetToken - the same as `Put`, and returns a token of the write for `GetAtLeast`
+func (c *Cache) PutAndGetToken(key string, val interface{}) Token {
+	return Token{hashCode(key) & c.mask, c.put(key, val, 0, 0, FromPut)}
+}
+
+// GetAtLeast - the same as `Get`, but observes at least the write of `tok` to `key`, for workflows rendering right after writing,
+// it blocks until the bucket is thawed if the write is still deferred by `FreezeShard`,
+// it may still miss if the item is deleted, evicted or expired since then
+func (c *Cache) GetAtLeast(key string, tok Token) (v interface{}, b bool) {
+	if c.distinct != nil {
+		c.distinct.add(key)
+	}
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	if tok.f != nil && tok.idx == idx {
+		for c.freezes[idx] == tok.f {
+			tok.f.cond.Wait()
+		}
+	}
+	v, b = c.lookup(key, idx)
+	c.locks[idx].Unlock()
+	if !b {
+		return nil, false
+	}
+	return c.deliver(key, v)
+}
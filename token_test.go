@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_GetAtLeast(t *testing.T) {
+	lc := NewLRUCache(1, 3, time.Second)
+	tok := lc.PutAndGetToken("1", "1")
+	if v, ok := lc.GetAtLeast("1", tok); !ok || v != "1" {
+		t.Error("case 1 failed")
+	}
+	if v, ok := lc.GetAtLeast("1", Token{}); !ok || v != "1" {
+		t.Error("case 2 failed")
+	}
+
+	lc.FreezeShard(0)
+	tok = lc.PutAndGetToken("1", "2")
+	if v, _ := lc.Get("1"); v != "1" {
+		t.Error("case 3 failed")
+	}
+	done := make(chan interface{})
+	go func() {
+		v, _ := lc.GetAtLeast("1", tok)
+		done <- v
+	}()
+	select {
+	case <-done:
+		t.Error("case 4 failed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	lc.Thaw(0)
+	if v := <-done; v != "2" {
+		t.Error("case 5 failed")
+	}
+
+	// frozen again, the former write is applied already
+	lc.FreezeShard(0)
+	if v, ok := lc.GetAtLeast("1", tok); !ok || v != "2" {
+		t.Error("case 6 failed")
+	}
+	lc.Thaw(0)
+}
@@ -0,0 +1,41 @@
+package cache
+
+import "time"
+
+// tombstone of a deleted key
+type tomb struct {
+	at  int64 // when it's deleted, by the clock of the cache
+	ver int64 // wall clock nano timestamp of the deletion, compared with versions of `PutIfNewer`
+}
+
+// Tombstone - reject puts of a deleted key for `window` after `Del`, unless they carry a newer version
+// (by `PutIfNewer` with a `ts` after the deletion), so a racing stale put from a slow loader
+// or replica can't resurrect deleted data, `ReplaceAll` is not affected
+func (c *Cache) Tombstone(window time.Duration) *Cache {
+	c.tombWindow = window
+	c.tombs = nil
+	if window > 0 {
+		c.tombs = make([]side[tomb], len(c.insts))
+	}
+	return c
+}
+
+// record the tombstone of key, lock of the bucket must be held
+func (c *Cache) bury(key string, idx int) {
+	now := c.clock.Now()
+	c.tombs[idx].set(key, tomb{now, time.Now().UnixNano()}, func(_ string, t tomb) bool { return now-t.at > int64(c.tombWindow) })
+}
+
+// whether a put of version `ver` is rejected by the tombstone of key, lock of the bucket must be held
+// the tombstone is removed once it's expired or a newer version is accepted
+func (c *Cache) buried(key string, idx int, ver int64) bool {
+	t, ok := c.tombs[idx].m[key]
+	if !ok {
+		return false
+	}
+	if ver <= t.ver && c.clock.Now()-t.at <= int64(c.tombWindow) {
+		return true
+	}
+	c.tombs[idx].del(key)
+	return false
+}
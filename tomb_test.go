@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Tombstone(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Hour).Tombstone(time.Second).Clock(clk)
+	before := time.Now()
+	lc.Put("1", "1")
+	lc.Del("1")
+	lc.Put("1", "stale")
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 1 failed")
+	}
+	if lc.PutIfAbsent("1", "stale") || lc.PutIfNewer("1", "stale", before) {
+		t.Error("case 2 failed")
+	}
+	if !lc.PutIfNewer("1", "new", time.Now()) {
+		t.Error("case 3 failed")
+	}
+	lc.Put("1", "2") // tombstone is removed by the newer version
+	if v, ok := lc.Get("1"); !ok || v != "2" {
+		t.Error("case 4 failed")
+	}
+
+	lc.Del("1")
+	clk.Add(time.Second + 1)
+	lc.Put("1", "3") // window passed
+	if v, ok := lc.Get("1"); !ok || v != "3" || len(lc.tombs[0].m) != 0 {
+		t.Error("case 5 failed")
+	}
+
+	for i := 0; i < purgeEvery-1; i++ {
+		lc.Del(strconv.Itoa(i))
+	}
+	clk.Add(time.Second + 1)
+	lc.Del("x") // purges the expired ones
+	if len(lc.tombs[0].m) != 1 {
+		t.Error("case 6 failed: ", len(lc.tombs[0].m))
+	}
+
+	lc.Tombstone(0)
+	lc.Del("1")
+	lc.Put("1", "4")
+	if v, ok := lc.Get("1"); !ok || v != "4" {
+		t.Error("case 7 failed")
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// bytes per padded slot, 128 bytes covers a cache line and its adjacent-line prefetch
+const padSize = 128
+
+// buckets per P with `AutoBuckets`
+const bucketsPerP = 4
+
+// AutoBuckets - pass it as `bucketCnt` of `NewLRUCache` to use a multiple of GOMAXPROCS (4 buckets per P)
+const AutoBuckets = -1
+
+func autoBuckets() int {
+	return bucketsPerP * runtime.GOMAXPROCS(0)
+}
+
+// lock of a bucket with the counters it guards
+type locked struct {
+	sync.Mutex
+	cnts counters
+}
+
+// lock and counters of a bucket, counters are only updated by the holder of the lock, so they share its cache line,
+// padded to a multiple of 128 bytes, so once the first one is aligned by `PadLocks` each one owns its cache lines
+// (the counters fill the line anyway, so the padding costs little), it holds no pointers
+type slot struct {
+	_ [(padSize - unsafe.Sizeof(locked{})%padSize) % padSize]byte // leading, a trailing empty array would be padded
+	locked
+}
+
+// allocate `n` slots starting at a multiple of `padSize` in a byte buffer, it's safe as slots hold no pointers
+func alignedSlots(n int) []slot {
+	buf := make([]byte, uintptr(n)*unsafe.Sizeof(slot{})+padSize)
+	off := (padSize - uintptr(unsafe.Pointer(&buf[0]))%padSize) % padSize
+	return unsafe.Slice((*slot)(unsafe.Pointer(&buf[off])), n)
+}
+
+// PadLocks - align each bucket lock (with counters of the bucket) to its own cache lines to avoid false sharing between cores,
+// so neither locking nor counting of a bucket invalidates the cache line of another one,
+// it's worth on many-core (especially NUMA) boxes with heavy racing, and costs 128 bytes at most
+// call it right after `NewLRUCache`, before the cache is used
+func (c *Cache) PadLocks() *Cache {
+	c.locks = alignedSlots(len(c.locks))
+	return c
+}
+
+// PinnedJanitor - the same as `Janitor`, but with a goroutine per P, each of which owns a contiguous range of buckets
+// and is locked to its own os thread, so the buckets stay with the same thread (and the cores the os keeps it on)
+// instead of bouncing between cores, it's worth on many-core (especially NUMA) boxes with `bucketCnt` of `AutoBuckets`
+// and `PadLocks`, the os decides which cores the threads run on
+func (c *Cache) PinnedJanitor(interval time.Duration) *Cache {
+	return c.startJanitor(interval, runtime.GOMAXPROCS(0), true)
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"runtime"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func Test_PadLocks(t *testing.T) {
+	lc := NewLRUCache(4, 1, time.Second).PadLocks()
+	if len(lc.locks) != 4 || unsafe.Sizeof(slot{})%padSize != 0 {
+		t.Error("case 1 failed")
+	}
+	for i := range lc.locks {
+		if uintptr(unsafe.Pointer(&lc.locks[i]))%padSize != 0 {
+			t.Error("case 2 failed")
+		}
+		if uintptr(unsafe.Pointer(&lc.locks[i].cnts))-uintptr(unsafe.Pointer(&lc.locks[i].Mutex)) >= padSize {
+			t.Error("case 3 failed")
+		}
+	}
+	lc.Put("1", "1")
+	if v, ok := lc.Get("1"); !ok || v != "1" {
+		t.Error("case 4 failed")
+	}
+	if s := lc.Stats(); s.Puts != 1 || s.Hits != 1 {
+		t.Error("case 5 failed")
+	}
+}
+
+func Test_autoBuckets(t *testing.T) {
+	lc := NewLRUCache(AutoBuckets, 1, time.Second)
+	if len(lc.insts) != nextPowOf2(bucketsPerP*runtime.GOMAXPROCS(0)) || len(lc.locks) != len(lc.insts) {
+		t.Error("case 1 failed")
+	}
+	if lc = NewLRUCache(0, 1, time.Second); len(lc.insts) != 1 {
+		t.Error("case 2 failed")
+	}
+}
+
+func Test_PinnedJanitor(t *testing.T) {
+	evs := make(chan string, 64)
+	clk := &fakeClock{}
+	lc := NewLRUCache(8, 4, time.Second).Clock(clk).OnEvict(func(key string, _ interface{}, _ EvictReason) {
+		evs <- key
+	})
+	for i := 0; i < 16; i++ {
+		lc.Put(string(rune('a'+i)), i)
+	}
+	lc.PutWithTTL("z", 0, time.Minute)
+	clk.Add(2 * time.Second)
+	lc.PinnedJanitor(time.Millisecond)
+	seen := map[string]bool{}
+	for len(seen) < 16 {
+		select {
+		case k := <-evs:
+			seen[k] = true
+		case <-time.After(time.Second):
+			t.Fatal("case 1 failed", len(seen))
+		}
+	}
+	lc.Close()
+	if lc.Len() != 1 || seen["z"] {
+		t.Error("case 2 failed")
+	}
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TraceRecord - a change of a traced key, see `Trace`
+type TraceRecord struct {
+	Time   time.Time
+	Key    string
+	Event  string      // "set", "rejected" (costs more than the budget), "promote", "demote", "expire" (deadline changed), "rename_from", "rename_to", or `EvictReason` of leaving
+	Source Source      // path that installed the item, for "set" only
+	Tag    interface{} // of the write that caused it, see `WithTag`
+}
+
+type tracer struct {
+	pattern string
+	mu      sync.Mutex // buckets record concurrently
+	ring    []TraceRecord
+	next    int
+	full    bool
+}
+
+// Trace - record every change of keys matching `pattern` (glob-style as `KeyspaceEvents`) with wall clock time into
+// a ring buffer of the latest `size` records, so specific keys can be investigated (e.g. for a support escalation)
+// without tracing the whole cache, see `TraceLog`, `size` of `0` (or negative) disables it
+func (c *Cache) Trace(pattern string, size int) *Cache {
+	if size <= 0 {
+		c.tracer = nil
+		return c
+	}
+	c.tracer = &tracer{pattern: pattern, ring: make([]TraceRecord, size)}
+	return c
+}
+
+// TraceLog - get the records of `Trace` from the oldest to the latest, nil if it's disabled
+func (c *Cache) TraceLog() []TraceRecord {
+	t := c.tracer
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	log := make([]TraceRecord, 0, len(t.ring))
+	if t.full {
+		log = append(log, t.ring[t.next:]...)
+	}
+	return append(log, t.ring[:t.next]...)
+}
+
+func (t *tracer) record(event, key string, src Source, tag interface{}) {
+	if !globMatch(t.pattern, key) {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	t.ring[t.next] = TraceRecord{now, key, event, src, tag}
+	if t.next++; t.next == len(t.ring) {
+		t.next, t.full = 0, true
+	}
+	t.mu.Unlock()
+}
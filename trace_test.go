@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Trace(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 2, time.Second).LFU(2).Clock(clk).Trace("user:*", 5)
+	if lc.TraceLog() == nil || len(lc.TraceLog()) != 0 {
+		t.Error("case 1 failed")
+	}
+	lc.Put("user:1", 1)
+	lc.Put("item:1", 1)
+	lc.Get("user:1")
+	lc.Put("user:1", 2)
+	lc.Del("user:1")
+	want := []TraceRecord{
+		{Key: "user:1", Event: "set", Source: FromPut},
+		{Key: "user:1", Event: "promote"},
+		{Key: "user:1", Event: "set", Source: FromPut},
+		{Key: "user:1", Event: "deleted"},
+		{Key: "user:1", Event: "deleted"}, // the former one in level-1
+	}
+	check := func(n int, log []TraceRecord, want []TraceRecord) {
+		if len(log) != len(want) {
+			t.Error("case", n, "failed", log)
+			return
+		}
+		for i := range want {
+			if log[i].Key != want[i].Key || log[i].Event != want[i].Event || log[i].Source != want[i].Source || log[i].Time.IsZero() {
+				t.Error("case", n, "failed", i, log[i])
+			}
+		}
+	}
+	check(2, lc.TraceLog(), want)
+
+	// only the latest ones are kept
+	lc.GetOrLoadWith("user:2", func(string) (interface{}, error) { return 2, nil })
+	clk.Add(2 * time.Second)
+	lc.Get("user:2")
+	check(3, lc.TraceLog(), append(want[2:], TraceRecord{Key: "user:2", Event: "set", Source: FromLoader},
+		TraceRecord{Key: "user:2", Event: "expired"}))
+
+	if NewLRUCache(1, 2, 0).TraceLog() != nil {
+		t.Error("case 4 failed")
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import "sync/atomic"
+
+// Transform - a stage of the value pipeline, e.g. compression, encryption, encoding or cloning
+// `Decode` is applied on `Get`, and its inverse `Encode` on `Put`, nil means the value passes through
+// an error on `Get` turns the hit into a miss, an error on `Put` deletes the key instead of storing
+type Transform struct {
+	Name   string
+	Encode func(v interface{}) (interface{}, error)
+	Decode func(v interface{}) (interface{}, error)
+}
+
+// stage with a switch
+type stage struct {
+	Transform
+	off int32
+}
+
+type pipeline struct {
+	stages []stage // in the order of read
+}
+
+// apply inverse stages in reverse order
+func (p *pipeline) encode(v interface{}) (_ interface{}, ok bool) {
+	var err error
+	for i := len(p.stages) - 1; i >= 0; i-- {
+		s := &p.stages[i]
+		if s.Encode == nil || atomic.LoadInt32(&s.off) != 0 {
+			continue
+		}
+		if v, err = s.Encode(v); err != nil {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func (p *pipeline) decode(v interface{}) (_ interface{}, ok bool) {
+	var err error
+	for i := range p.stages {
+		s := &p.stages[i]
+		if s.Decode == nil || atomic.LoadInt32(&s.off) != 0 {
+			continue
+		}
+		if v, err = s.Decode(v); err != nil {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Pipeline - configure the ordered pipeline of transforms applied to values on read,
+// e.g. decompress -> decrypt -> decode -> clone, and their inverses in reverse order on write
+// it replaces the former pipeline, call it with no stages to remove it
+func (c *Cache) Pipeline(stages ...Transform) *Cache {
+	if len(stages) == 0 {
+		c.pipeline = nil
+		return c
+	}
+	p := &pipeline{make([]stage, len(stages))}
+	for i := range stages {
+		p.stages[i].Transform = stages[i]
+	}
+	c.pipeline = p
+	return c
+}
+
+// Stage - switch on/off the stage of pipeline by name, returns false if there's no such stage
+// items stored while a stage is off are not transformed by it, so switch only stages that tolerate it (e.g. clone)
+func (c *Cache) Stage(name string, on bool) bool {
+	if c.pipeline == nil {
+		return false
+	}
+	found := false
+	for i := range c.pipeline.stages {
+		if s := &c.pipeline.stages[i]; s.Name == name {
+			if on {
+				atomic.StoreInt32(&s.off, 0)
+			} else {
+				atomic.StoreInt32(&s.off, 1)
+			}
+			found = true
+		}
+	}
+	return found
+}
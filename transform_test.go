@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Pipeline(t *testing.T) {
+	var trace []string
+	lc := NewLRUCache(1, 3, time.Second).Pipeline(
+		Transform{Name: "upper",
+			Decode: func(v interface{}) (interface{}, error) {
+				trace = append(trace, "upper.decode")
+				return strings.ToLower(v.(string)), nil
+			},
+			Encode: func(v interface{}) (interface{}, error) {
+				trace = append(trace, "upper.encode")
+				if v.(string) == "" {
+					return nil, errors.New("empty")
+				}
+				return strings.ToUpper(v.(string)), nil
+			}},
+		Transform{Name: "suffix",
+			Decode: func(v interface{}) (interface{}, error) {
+				trace = append(trace, "suffix.decode")
+				return v.(string) + "!", nil
+			}})
+
+	lc.Put("1", "a")
+	if v, _ := lc.insts[0][0].get("1"); v.v != "A" {
+		t.Error("case 1 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != "a!" {
+		t.Error("case 2 failed: ", v)
+	}
+	if strings.Join(trace, ",") != "upper.encode,upper.decode,suffix.decode" {
+		t.Error("case 3 failed: ", trace)
+	}
+
+	lc.Put("1", "") // encode error removes the former item
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 4 failed")
+	}
+
+	if !lc.Stage("suffix", false) || lc.Stage("none", false) {
+		t.Error("case 5 failed")
+	}
+	lc.Put("1", "b")
+	if v, ok := lc.Get("1"); !ok || v != "b" {
+		t.Error("case 6 failed: ", v)
+	}
+
+	lc.Pipeline()
+	if v, ok := lc.Get("1"); !ok || v != "B" || lc.Stage("suffix", true) {
+		t.Error("case 7 failed: ", v)
+	}
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// expiration of items that never expire
+const never = math.MaxInt64
+
+// PutWithTTL - put a item into cache that expires after `ttl` instead of `expire` of the level, `0` (or negative) for never
+func (c *Cache) PutWithTTL(key string, val interface{}, ttl time.Duration) {
+	c.put(key, val, c.ttl(ttl), 0, FromPut)
+}
+
+// expiration after `ttl` by the clock of the cache, never for `0` (or negative)
+func (c *Cache) ttl(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return never
+	}
+	return after(c.clock.Now(), int64(ttl))
+}
+
+// `now` plus `d` nanoseconds, saturated to never instead of overflowing
+func after(now, d int64) int64 {
+	if d > never-now {
+		return never
+	}
+	return now + d
+}
+
+// PutUntil - put a item into cache that expires at wall clock `deadline` instead of after `expire` of the level,
+// e.g. close of an auction or `exp` claim of a token, it's converted to the clock of the cache when it's put,
+// so later jumps of wall clock don't affect it
+func (c *Cache) PutUntil(key string, val interface{}, deadline time.Time) {
+	c.put(key, val, c.deadline(deadline), 0, FromPut)
+}
+
+// expiration of wall clock `t` by the clock of the cache
+func (c *Cache) deadline(t time.Time) int64 {
+	if exp := after(c.clock.Now(), int64(time.Until(t))); exp != 0 {
+		return exp
+	}
+	return -1 // 0 means following `expire` of the level
+}
+
+// ExpireAt - change the live item of `key` to expire at wall clock `deadline`, report whether it's live,
+// the item is expired right away if `deadline` has passed
+func (c *Cache) ExpireAt(key string, deadline time.Time) bool {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	if c.freezes[idx] != nil {
+		c.thawed(idx) // deferred writes may change the item
+	}
+	w, level := c.peek(key, idx)
+	if w == nil {
+		return false
+	}
+	w.exp = c.deadline(deadline)
+	if w.watch != nil {
+		c.rearm(key, idx, level, w)
+	}
+	if c.subs != nil {
+		c.notify("expire", key, nil)
+	}
+	if c.tracer != nil {
+		c.tracer.record("expire", key, 0, nil)
+	}
+	return true
+}
+
+// budget of the janitor per bucket
+type sweepBudget struct {
+	wait time.Duration // for the lock
+	n    int           // items walked per hold of the lock
+}
+
+type janitor struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Janitor - start a background goroutine that walks all buckets every `interval` and evicts the expired items,
+// so long-tail keys won't hold memory until they rotate out, call `Close` to stop it
+// it's a no-op if the janitor is already running, see `Sweep` for the goroutine-free way
+// `OnEvict` is called in the janitor goroutine for items it evicts
+func (c *Cache) Janitor(interval time.Duration) *Cache {
+	return c.startJanitor(interval, 1, false)
+}
+
+// start `workers` janitor goroutines, each of which owns a contiguous range of buckets, locked to its own os thread if `pinned`
+func (c *Cache) startJanitor(interval time.Duration, workers int, pinned bool) *Cache {
+	if c.janitor != nil {
+		return c
+	}
+	if workers > len(c.insts) {
+		workers = len(c.insts)
+	}
+	j := &janitor{stop: make(chan struct{}), done: make(chan struct{})}
+	c.janitor = j
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			if pinned {
+				runtime.LockOSThread()
+				defer runtime.UnlockOSThread()
+			}
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			for {
+				select {
+				case <-j.stop:
+					return
+				case <-t.C:
+					for idx := from; idx < to; idx++ {
+						c.janitorSweep(idx)
+					}
+				}
+			}
+		}(w*len(c.insts)/workers, (w+1)*len(c.insts)/workers)
+	}
+	go func() {
+		wg.Wait()
+		close(j.done)
+	}()
+	return c
+}
+
+// JanitorBudget - make the janitor yield to foreground operations: it waits at most `wait` for the lock of a bucket
+// (`0` to try only once) and skips the bucket till the next round if it's still held, counted in `SweepSkips` of `Stats`,
+// and walks at most `n` items per hold of the lock (`0` for all), releasing it in between,
+// so background maintenance never holds up `Put`/`Get` for long, call it before `Janitor`
+func (c *Cache) JanitorBudget(wait time.Duration, n int) *Cache {
+	if n <= 0 {
+		n = math.MaxInt
+	}
+	c.sweepBgt = sweepBudget{wait, n}
+	return c
+}
+
+// sweep bucket `idx` by the janitor, within the budget of `JanitorBudget` if any
+func (c *Cache) janitorSweep(idx int) {
+	if c.sweepBgt.n == 0 { // no budget
+		c.lock(idx)
+		c.step(idx, math.MaxInt)
+		if c.trash != nil {
+			c.purgeTrash(idx)
+		}
+		c.locks[idx].Unlock()
+		return
+	}
+	for walked, total := 0, 0; ; walked += c.sweepBgt.n {
+		if !c.tryLock(idx, c.sweepBgt.wait) {
+			atomic.AddUint64(&c.sweepSkips, 1)
+			return
+		}
+		if total == 0 { // walk each level once in all
+			for _, inst := range c.insts[idx] {
+				if inst != nil && inst.length() > total {
+					total = inst.length()
+				}
+			}
+		}
+		c.step(idx, c.sweepBgt.n)
+		last := walked+c.sweepBgt.n >= total
+		if last && c.trash != nil {
+			c.purgeTrash(idx)
+		}
+		c.locks[idx].Unlock()
+		if last {
+			return
+		}
+	}
+}
+
+// try the lock of bucket `idx` for at most `wait`, without recording the contention as `lock` does
+func (c *Cache) tryLock(idx int, wait time.Duration) bool {
+	if c.locks[idx].TryLock() {
+		return true
+	}
+	for t := time.Now(); time.Since(t) < wait; {
+		runtime.Gosched()
+		if c.locks[idx].TryLock() {
+			return true
+		}
+	}
+	return false
+}
+
+// Close - stop background goroutines started by the cache (i.e. `Janitor`, `Archive` and `OnEvictAsync`), it waits for them to exit
+// the cache is still usable after closed
+func (c *Cache) Close() {
+	if j := c.janitor; j != nil {
+		j.once.Do(func() { close(j.stop) })
+		<-j.done
+		c.janitor = nil
+	}
+	if c.archiver != nil {
+		c.stopArchiver()
+	}
+	if c.evictQ != nil {
+		c.stopEvictQ()
+	}
+}
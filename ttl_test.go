@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_PutWithTTL(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).LFU(3).Clock(clk)
+	lc.PutWithTTL("1", "1", time.Minute)
+	lc.PutWithTTL("2", "2", 0)
+	lc.Put("3", "3")
+	lc.Get("1") // l0 -> l1, keeps its ttl
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 1 failed")
+	}
+	if _, ok := lc.Get("3"); ok {
+		t.Error("case 2 failed")
+	}
+	clk.Add(time.Minute)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 3 failed")
+	}
+	if _, ok := lc.Get("2"); !ok {
+		t.Error("case 4 failed")
+	}
+
+	lc = NewLRUCache(1, 3, 0).Clock(clk)
+	lc.PutWithTTL("1", "1", time.Second)
+	lc.Put("2", "2")
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 5 failed")
+	}
+	if _, ok := lc.Get("2"); !ok {
+		t.Error("case 6 failed")
+	}
+
+	// saturated instead of overflowing
+	lc = NewLRUCache(1, 3, time.Second).Clock(clk)
+	lc.PutWithTTL("1", "1", time.Duration(math.MaxInt64))
+	lc.PutWithTTL("2", "2", time.Duration(math.MaxInt64-clk.Now()+1))
+	clk.Add(time.Hour)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 7 failed")
+	}
+	if _, ok := lc.Get("2"); !ok {
+		t.Error("case 8 failed")
+	}
+
+	lc = NewLRUCache(1, 3, time.Hour)
+	lc.PutWithTTL("1", "1", 20*time.Millisecond)
+	ch, _ := lc.ExpireAfter("1")
+	if !closed(ch, time.Second) {
+		t.Error("case 9 failed")
+	}
+}
+
+func Test_Janitor(t *testing.T) {
+	type ev struct {
+		key    string
+		reason EvictReason
+	}
+	evs := make(chan ev, 10)
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).Clock(clk).OnEvict(func(key string, _ interface{}, reason EvictReason) {
+		evs <- ev{key, reason}
+	})
+	lc.Put("1", "1")
+	lc.PutWithTTL("2", "2", time.Minute)
+	clk.Add(2 * time.Second)
+	lc.Janitor(time.Millisecond)
+	lc.Janitor(time.Millisecond)
+	select {
+	case e := <-evs:
+		if e.key != "1" || e.reason != Expired {
+			t.Error("case 1 failed", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("case 2 failed")
+	}
+	lc.Close()
+	lc.Close()
+	if lc.ShardStats(0).Len != 1 {
+		t.Error("case 3 failed")
+	}
+	select {
+	case e := <-evs:
+		t.Error("case 4 failed", e)
+	default:
+	}
+}
+
+func Test_PutUntil(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).Clock(clk)
+	lc.PutUntil("1", "1", time.Now().Add(time.Minute))
+	lc.PutUntil("2", "2", time.Now().Add(-time.Second))
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 1 failed")
+	}
+	if _, ok := lc.Get("2"); ok {
+		t.Error("case 2 failed")
+	}
+	clk.Add(time.Minute)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 3 failed")
+	}
+	lc.PutUntil("3", "3", time.Date(2500, 1, 1, 0, 0, 0, 0, time.UTC)) // saturated instead of overflowing
+	if _, ok := lc.Get("3"); !ok {
+		t.Error("case 4 failed")
+	}
+}
+
+func Test_ExpireAt(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).Clock(clk)
+	if lc.ExpireAt("1", time.Now().Add(time.Minute)) {
+		t.Error("case 1 failed")
+	}
+	lc.Put("1", "1")
+	if !lc.ExpireAt("1", time.Now().Add(time.Minute)) {
+		t.Error("case 2 failed")
+	}
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 3 failed")
+	}
+	lc.ExpireAt("1", time.Now().Add(-time.Second))
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 4 failed")
+	}
+
+	// watched
+	lc = NewLRUCache(1, 3, 0)
+	lc.Put("1", "1")
+	ch, _ := lc.ExpireAfter("1")
+	lc.ExpireAt("1", time.Now().Add(10*time.Millisecond))
+	if !closed(ch, time.Second) {
+		t.Error("case 5 failed")
+	}
+	lc.PutWithTTL("2", "2", 10*time.Millisecond)
+	ch, _ = lc.ExpireAfter("2")
+	lc.ExpireAt("2", time.Now().Add(time.Hour))
+	if closed(ch, 50*time.Millisecond) {
+		t.Error("case 6 failed")
+	}
+}
+
+func Test_JanitorBudget(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 8, time.Second).LFU(2).Clock(clk).JanitorBudget(time.Millisecond, 1)
+	for _, k := range []string{"1", "2", "3", "4"} {
+		lc.Put(k, k)
+	}
+	lc.Get("1")
+	clk.Add(2 * time.Second)
+	lc.locks[0].Lock() // held by foreground
+	lc.Janitor(time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadUint64(&lc.sweepSkips) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if lc.insts[0][0].length() != 3 || lc.insts[0][1].length() != 1 {
+		t.Error("case 1 failed")
+	}
+	lc.locks[0].Unlock()
+	for lc.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	lc.Close()
+	if s := lc.Stats(); s.SweepSkips == 0 || s.Len+s.LFULen != 0 {
+		t.Error("case 2 failed")
+	}
+}
@@ -0,0 +1,55 @@
+package cache
+
+// Typed - a type-safe view of `Cache`, no type assertion at call sites
+// values are still boxed inside, since stages of `Pipeline` may store them as other types,
+// see `TypedCache` for a type-safe cache storing values as they are, if the other features of `Cache` aren't needed
+type Typed[K comparable, V any] struct {
+	c   *Cache
+	key func(k K) string
+}
+
+// NewTyped - create a type-safe view of `c` with string keys, configure `c` before wrapping, e.g.
+// var users = cache.NewTyped[*UserInfo](cache.NewLRUCache(16, 200, 10 * time.Second).LFU(1024))
+func NewTyped[V any](c *Cache) *Typed[string, V] {
+	return &Typed[string, V]{c, func(k string) string { return k }}
+}
+
+// NewTypedBy - create a type-safe view of `c` with keys of any comparable type, `key` formats a key as string,
+// which must be unique for each key, e.g. strconv.FormatInt for int64 ids
+func NewTypedBy[K comparable, V any](c *Cache, key func(k K) string) *Typed[K, V] {
+	return &Typed[K, V]{c, key}
+}
+
+// Cache - get the underlying cache for the other interfaces
+func (t *Typed[K, V]) Cache() *Cache {
+	return t.c
+}
+
+// Put - put a item into cache
+func (t *Typed[K, V]) Put(key K, val V) {
+	t.c.Put(t.key(key), val)
+}
+
+// Get - get value of key from cache with result, the value is zero if it's the nil sentinel,
+// it's a miss if the value is not of type V (e.g. put through `Cache()` by mistake)
+func (t *Typed[K, V]) Get(key K) (V, bool) {
+	v, ok := t.c.Get(t.key(key))
+	tv, typed := v.(V)
+	return tv, ok && (typed || v == nil)
+}
+
+// Update - atomically replace the value of live `key` with what f returns, and report whether the key is live,
+// a value not of type V is kept as it is, f is called with the bucket locked, so it must not call back into the cache
+func (t *Typed[K, V]) Update(key K, f func(v V) V) bool {
+	return t.c.Update(t.key(key), func(v interface{}) interface{} {
+		if tv, ok := v.(V); ok || v == nil {
+			return f(tv)
+		}
+		return v
+	})
+}
+
+// Del - delete item by key from cache
+func (t *Typed[K, V]) Del(key K) {
+	t.c.Del(t.key(key))
+}
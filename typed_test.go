@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Typed(t *testing.T) {
+	tc := NewTyped[int](NewLRUCache(1, 3, time.Second))
+	tc.Put("1", 1)
+	if v, ok := tc.Get("1"); !ok || v != 1 {
+		t.Error("case 1 failed")
+	}
+	tc.Cache().Put("2", nil) // nil sentinel
+	if v, ok := tc.Get("2"); !ok || v != 0 {
+		t.Error("case 2 failed")
+	}
+	tc.Del("1")
+	if _, ok := tc.Get("1"); ok {
+		t.Error("case 3 failed")
+	}
+
+	type user struct{ name string }
+	uc := NewTypedBy[int64, *user](NewLRUCache(1, 3, time.Second), func(k int64) string {
+		return strconv.FormatInt(k, 10)
+	})
+	uc.Put(1, &user{"a"})
+	if v, ok := uc.Get(1); !ok || v.name != "a" {
+		t.Error("case 4 failed")
+	}
+	if v, ok := uc.Cache().Get("1"); !ok || v.(*user).name != "a" {
+		t.Error("case 5 failed")
+	}
+	if v, ok := uc.Get(2); ok || v != nil {
+		t.Error("case 6 failed")
+	}
+
+	// wrong type
+	tc.Cache().Put("3", "3")
+	if v, ok := tc.Get("3"); ok || v != 0 {
+		t.Error("case 7 failed")
+	}
+}
+
+func Test_TypedUpdate(t *testing.T) {
+	tc := NewTyped[int](NewLRUCache(1, 3, time.Second))
+	tc.Put("1", 1)
+	if !tc.Update("1", func(v int) int { return v + 1 }) {
+		t.Error("case 1 failed")
+	}
+	if v, ok := tc.Get("1"); !ok || v != 2 {
+		t.Error("case 2 failed")
+	}
+	if tc.Update("none", func(v int) int { return v + 1 }) {
+		t.Error("case 3 failed")
+	}
+	tc.Cache().Put("2", nil) // nil sentinel
+	tc.Update("2", func(v int) int { return v + 1 })
+	if v, ok := tc.Get("2"); !ok || v != 1 {
+		t.Error("case 4 failed")
+	}
+	tc.Cache().Put("3", "3")
+	tc.Update("3", func(v int) int { return v + 1 })
+	if v, ok := tc.Cache().Get("3"); !ok || v != "3" {
+		t.Error("case 5 failed")
+	}
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// a typed item, stored in nodes as it is
+type item[V any] struct {
+	v  V
+	ts int64 // nano timestamp, 0 if items never expire
+}
+
+// TypedCache - a type-safe cache with keys of any comparable type, built on the same generic core (the lru
+// of each bucket) as `Cache`, values are stored as they are and keys are hashed as they are, so there's neither
+// type assertion at call sites nor boxing of values into interface{} or formatting of keys to strings,
+// it has lru (and lfu-2 by `LFU`) with expiration only, use `Cache` (or `Typed` over it) for the other features
+type TypedCache[K comparable, V any] struct {
+	locks  []sync.Mutex
+	insts  [][2]*cache[K, item[V]] // level-0 for normal LRU, level-1 for LFU-2
+	mask   int
+	expire time.Duration
+	hash   func(k K) int
+	clock  Clock
+}
+
+// NewTypedCache - create a type-safe lru cache, parameters are the same as `NewLRUCache`, e.g.
+// var users = cache.NewTypedCache[int64, *UserInfo](16, 200, 10 * time.Second).LFU(1024)
+func NewTypedCache[K comparable, V any](bucketCnt int, capPerBkt int, expire time.Duration) *TypedCache[K, V] {
+	if bucketCnt == AutoBuckets {
+		bucketCnt = autoBuckets()
+	}
+	size := nextPowOf2(bucketCnt)
+	c := &TypedCache[K, V]{locks: make([]sync.Mutex, size), insts: make([][2]*cache[K, item[V]], size),
+		mask: size - 1, expire: expire, hash: hasher[K](), clock: sysClock{}}
+	for i := range c.insts {
+		c.insts[i][0] = create[K, item[V]](capPerBkt)
+	}
+	return c
+}
+
+// LFU - add lfu support, see `Cache.LFU`
+func (c *TypedCache[K, V]) LFU(capPerBkt int) *TypedCache[K, V] {
+	for i := range c.insts {
+		c.insts[i][1] = create[K, item[V]](capPerBkt)
+	}
+	return c
+}
+
+// Clock - replace the time source of the cache, see `Cache.Clock`
+func (c *TypedCache[K, V]) Clock(clk Clock) *TypedCache[K, V] {
+	if clk == nil {
+		clk = sysClock{}
+	}
+	c.clock = clk
+	return c
+}
+
+// timestamp for a new item, skip reading the clock if items never expire
+func (c *TypedCache[K, V]) now() int64 {
+	if c.expire <= 0 {
+		return 0
+	}
+	return c.clock.Now()
+}
+
+func (c *TypedCache[K, V]) expired(it item[V]) bool {
+	return c.expire > 0 && c.clock.Now()-it.ts > int64(c.expire)
+}
+
+// Put - put a item into cache
+func (c *TypedCache[K, V]) Put(key K, val V) {
+	idx := c.hash(key) & c.mask
+	c.locks[idx].Lock()
+	c.insts[idx][0].put(key, item[V]{val, c.now()})
+	c.locks[idx].Unlock()
+}
+
+// Get - get value of key from cache with result
+func (c *TypedCache[K, V]) Get(key K) (v V, b bool) {
+	idx := c.hash(key) & c.mask
+	c.locks[idx].Lock()
+	var it item[V]
+	if c.insts[idx][1] == nil {
+		// normal lru mode
+		it, b = c.insts[idx][0].get(key)
+		b = b && !c.expired(it)
+	} else if it, b = c.insts[idx][0].del(key); !b {
+		// re-find in level-1
+		it, b = c.insts[idx][1].get(key)
+		b = b && !c.expired(it)
+	} else if b = !c.expired(it); b {
+		// find in level-0, move to level-1
+		c.insts[idx][1].put(key, it)
+	}
+	c.locks[idx].Unlock()
+	if !b {
+		return v, false
+	}
+	return it.v, true
+}
+
+// Update - atomically replace the value of live `key` with what f returns, and report whether the key is live,
+// it's replaced in place, keeping the level and age of the item, f is called with the bucket locked,
+// so it must not call back into the cache
+func (c *TypedCache[K, V]) Update(key K, f func(v V) V) bool {
+	idx := c.hash(key) & c.mask
+	c.locks[idx].Lock()
+	defer c.locks[idx].Unlock()
+	for _, inst := range c.insts[idx] {
+		if inst == nil {
+			continue
+		}
+		if e, ok := inst.hmap[key]; ok && !c.expired(e.v) {
+			e.v.v = f(e.v.v)
+			return true
+		}
+	}
+	return false
+}
+
+// Del - delete item by key from cache
+func (c *TypedCache[K, V]) Del(key K) {
+	idx := c.hash(key) & c.mask
+	c.locks[idx].Lock()
+	for _, inst := range c.insts[idx] {
+		if inst != nil {
+			inst.del(key)
+		}
+	}
+	c.locks[idx].Unlock()
+}
+
+// the hash function of keys of type K, strings are hashed as `Cache` does, numbers, booleans and pointers
+// by their bits, others (e.g. structs) by their values formatted by "%#v", which is slower
+// (and tells -0.0 from +0.0 in fields, keep such fields out of keys)
+func hasher[K comparable]() func(k K) int {
+	var k K
+	t := reflect.TypeOf(&k).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		return func(k K) int { return hashCode(*(*string)(unsafe.Pointer(&k))) }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Bool, reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		switch t.Size() {
+		case 1:
+			return func(k K) int { return mix(uint64(*(*uint8)(unsafe.Pointer(&k)))) }
+		case 2:
+			return func(k K) int { return mix(uint64(*(*uint16)(unsafe.Pointer(&k)))) }
+		case 4:
+			return func(k K) int { return mix(uint64(*(*uint32)(unsafe.Pointer(&k)))) }
+		case 8:
+			return func(k K) int { return mix(*(*uint64)(unsafe.Pointer(&k))) }
+		}
+	case reflect.Float32:
+		return func(k K) int { return mixFloat(float64(*(*float32)(unsafe.Pointer(&k)))) }
+	case reflect.Float64:
+		return func(k K) int { return mixFloat(*(*float64)(unsafe.Pointer(&k))) }
+	}
+	return func(k K) int { return hashCode(fmt.Sprintf("%#v", k)) }
+}
+
+// spread bits of an integer to the low bits used by masking (fibonacci hashing)
+func mix(x uint64) int {
+	return int(uint32((x * 0x9E3779B97F4A7C15) >> 32))
+}
+
+func mixFloat(f float64) int {
+	if f == 0 {
+		f = 0 // -0.0 equals +0.0
+	}
+	return mix(math.Float64bits(f))
+}
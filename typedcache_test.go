@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_TypedCache(t *testing.T) {
+	clk := &fakeClock{}
+	c := NewTypedCache[int64, string](2, 2, time.Second).Clock(clk)
+	c.Put(1, "1")
+	if v, ok := c.Get(1); !ok || v != "1" {
+		t.Error("case 1 failed")
+	}
+	if v, ok := c.Get(2); ok || v != "" {
+		t.Error("case 2 failed")
+	}
+	c.Del(1)
+	if _, ok := c.Get(1); ok {
+		t.Error("case 3 failed")
+	}
+	c.Put(1, "1")
+	clk.Add(time.Second + 1)
+	if _, ok := c.Get(1); ok {
+		t.Error("case 4 failed")
+	}
+
+	if c.Update(1, func(v string) string { return v + "1" }) {
+		t.Error("case 5 failed")
+	}
+	c.Put(1, "1")
+	if !c.Update(1, func(v string) string { return v + "1" }) {
+		t.Error("case 6 failed")
+	}
+	if v, ok := c.Get(1); !ok || v != "11" {
+		t.Error("case 7 failed")
+	}
+
+	// evicted by capacity
+	c = NewTypedCache[int64, string](1, 2, 0)
+	for i := int64(0); i < 3; i++ {
+		c.Put(i, strconv.FormatInt(i, 10))
+	}
+	if _, ok := c.Get(0); ok {
+		t.Error("case 8 failed")
+	}
+}
+
+func Test_TypedCacheLFU(t *testing.T) {
+	clk := &fakeClock{}
+	c := NewTypedCache[string, int](1, 2, time.Second).LFU(2).Clock(clk)
+	c.Put("1", 1)
+	c.Get("1") // l0 -> l1
+	c.Put("2", 2)
+	c.Put("3", 3)
+	c.Put("4", 4) // "2" is evicted from l0, "1" stays in l1
+	if v, ok := c.Get("1"); !ok || v != 1 {
+		t.Error("case 1 failed")
+	}
+	if _, ok := c.Get("2"); ok {
+		t.Error("case 2 failed")
+	}
+	c.Put("1", 11) // the newer one in l0
+	if !c.Update("1", func(v int) int { return v + 1 }) {
+		t.Error("case 3 failed")
+	}
+	if v, ok := c.Get("1"); !ok || v != 12 {
+		t.Error("case 4 failed: ", v)
+	}
+	c.Del("1")
+	if _, ok := c.Get("1"); ok {
+		t.Error("case 5 failed")
+	}
+	clk.Add(time.Second + 1)
+	if _, ok := c.Get("3"); ok { // expired in l0, not promoted
+		t.Error("case 6 failed")
+	}
+}
+
+func Test_hasher(t *testing.T) {
+	type id string
+	if hasher[id]()("abc") != hashCode("abc") || hasher[string]()("abc") != hashCode("abc") {
+		t.Error("case 1 failed")
+	}
+	if hasher[int8]()(-1) != mix(0xff) || hasher[uint16]()(1) != mix(1) || hasher[int32]()(1) != mix(1) || hasher[int]()(1) != mix(1) {
+		t.Error("case 2 failed")
+	}
+	if hasher[bool]()(true) != mix(1) {
+		t.Error("case 3 failed")
+	}
+	if hasher[float64]()(math.Copysign(0, -1)) != hasher[float64]()(0) || hasher[float32]()(1) != mixFloat(1) {
+		t.Error("case 4 failed")
+	}
+	type pair struct{ a, b int }
+	if h := hasher[pair](); h(pair{1, 2}) != h(pair{1, 2}) || h(pair{1, 2}) == h(pair{2, 1}) {
+		t.Error("case 5 failed")
+	}
+	p, q := &pair{}, &pair{}
+	if h := hasher[*pair](); h(p) != h(p) || h(p) == h(q) {
+		t.Error("case 6 failed")
+	}
+
+	// -0.0 and +0.0 are the same key
+	c := NewTypedCache[float64, int](16, 1, 0)
+	c.Put(0, 1)
+	if v, ok := c.Get(math.Copysign(0, -1)); !ok || v != 1 {
+		t.Error("case 7 failed")
+	}
+}
+
+func Test_TypedCacheNoAlloc(t *testing.T) {
+	c := NewTypedCache[int, int](4, 16, time.Hour).LFU(16)
+	for i := 0; i < 32; i++ {
+		c.Put(i, i)
+	}
+	i := 0
+	if n := testing.AllocsPerRun(1000, func() {
+		c.Get(i % 64)
+		c.Put(i%64, i) // new keys with eviction, and replacement
+		i++
+	}); n != 0 {
+		t.Error("case 1 failed", n)
+	}
+}
+
+func Test_TypedCacheConcurrent(t *testing.T) {
+	c := NewTypedCache[int, int](4, 8, time.Second).LFU(8)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			for i := 0; i < 1000; i++ {
+				k := (g + i) % 20
+				c.Put(k, k)
+				if v, ok := c.Get(k); ok && v != k {
+					t.Error("case 1 failed")
+				}
+				c.Update(k, func(v int) int { return v })
+				c.Del(k + 1)
+			}
+			wg.Done()
+		}(g)
+	}
+	wg.Wait()
+}
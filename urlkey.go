@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"net/url"
+	"strings"
+)
+
+// TrackingParams - common query params of click tracking, which don't change the content, "utm_*" matches by prefix
+var TrackingParams = []string{"utm_*", "gclid", "dclid", "fbclid", "msclkid", "mc_cid", "mc_eid", "_ga", "yclid"}
+
+// CanonicalURL - create a normalizer of url-shaped keys for `NewTypedBy`, so the same resource hits the same item:
+// scheme and host are lowercased, default port and fragment are removed, query params are sorted by name
+// and those in `strip` are removed ("prefix*" matches by prefix, e.g. `CanonicalURL(cache.TrackingParams...)`),
+// keys that fail to parse as url are kept as they are
+func CanonicalURL(strip ...string) func(key string) string {
+	return func(key string) string {
+		u, err := url.Parse(key)
+		if err != nil {
+			return key
+		}
+		u.Scheme, u.Host, u.Fragment, u.RawFragment = strings.ToLower(u.Scheme), strings.ToLower(u.Host), "", ""
+		if port := u.Port(); port == "80" && u.Scheme == "http" || port == "443" && u.Scheme == "https" {
+			u.Host = u.Host[:len(u.Host)-len(port)-1]
+		}
+		if u.Host != "" && u.Path == "" && u.Opaque == "" {
+			u.Path = "/"
+		}
+		if u.RawQuery != "" {
+			q := u.Query()
+			for name := range q {
+				if stripped(name, strip) {
+					delete(q, name)
+				}
+			}
+			u.RawQuery = q.Encode() // sorted by name
+		}
+		u.ForceQuery = false
+		return u.String()
+	}
+}
+
+// whether query param `name` matches any of `strip`
+func stripped(name string, strip []string) bool {
+	for _, s := range strip {
+		if strings.HasSuffix(s, "*") && strings.HasPrefix(name, s[:len(s)-1]) || s == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package cache
+
+import "testing"
+
+func Test_CanonicalURL(t *testing.T) {
+	canon := CanonicalURL(TrackingParams...)
+	cases := []struct{ key, want string }{
+		{"https://Example.COM/a?b=2&a=1", "https://example.com/a?a=1&b=2"},
+		{"HTTPS://example.com:443/a", "https://example.com/a"},
+		{"http://example.com:80", "http://example.com/"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+		{"https://example.com/a?utm_source=x&id=1&fbclid=y&utm_medium=z#top", "https://example.com/a?id=1"},
+		{"https://example.com/a?utm_source=x", "https://example.com/a"},
+		{"https://example.com/a?", "https://example.com/a"},
+		{"https://example.com/a?q=b+c&q=a", "https://example.com/a?q=b+c&q=a"},
+		{"https://example.com/a?q=b%20c", "https://example.com/a?q=b+c"},
+		{"https://example.com/Path", "https://example.com/Path"},
+		{"uid1", "uid1"},
+		{"%zz", "%zz"},
+	}
+	for i, c := range cases {
+		if got := canon(c.key); got != c.want {
+			t.Error("case 1 failed: ", i, got)
+		}
+	}
+
+	lc := NewTypedBy[string, int](NewLRUCache(1, 10, 0), CanonicalURL("ref"))
+	lc.Put("https://example.com/a?ref=1&x=1", 1)
+	if v, ok := lc.Get("https://EXAMPLE.com/a?x=1&ref=2"); !ok || v != 1 {
+		t.Error("case 2 failed")
+	}
+}
@@ -0,0 +1,63 @@
+package cache
+
+import "time"
+
+// Validate - confirm the item of key is still valid by a cheap check of its value against the origin (e.g. etag or version)
+// instead of a full reload, the item lives `ttl` longer from now if `valid` reports true (`0` to restart `expire` of its level,
+// unless it never expires), otherwise it's deleted, expired items work as well until they are evicted, e.g. to revalidate stale http responses
+// `valid` is called without the lock held, nothing is changed if the item is replaced or removed meanwhile
+// returns whether the item is confirmed and kept
+func (c *Cache) Validate(key string, ttl time.Duration, valid func(val interface{}) bool) bool {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	w, level := c.stored(key, idx)
+	if w == nil {
+		c.locks[idx].Unlock()
+		return false
+	}
+	v, ts, exp := w.v, w.ts, w.exp
+	c.locks[idx].Unlock()
+	if c.dryRun {
+		v = nil
+	} else if c.pipeline != nil {
+		var ok bool
+		if v, ok = c.pipeline.decode(v); !ok {
+			v = nil
+		}
+	}
+	ok := valid(v)
+
+	c.lock(idx)
+	defer c.locks[idx].Unlock()
+	if c.freezes[idx] != nil {
+		c.thawed(idx) // deferred writes may change the item
+	}
+	if cur, l := c.stored(key, idx); cur != w || l != level || w.ts != ts || w.exp != exp {
+		return false // replaced meanwhile (wrappers may be recycled)
+	}
+	if !ok {
+		c.remove(key, idx)
+		return false
+	}
+	now := c.clock.Now()
+	stale := c.expired(w, now, level)
+	w.ts = now
+	if ttl > 0 {
+		w.exp = after(now, int64(ttl))
+	} else if w.exp != never {
+		w.exp = 0
+	}
+	if w.watch != nil {
+		c.rearm(key, idx, level, w)
+	}
+	if c.onState != nil && stale {
+		c.transit(key, Stale, Ready)
+	}
+	if c.subs != nil {
+		c.notify("expire", key, nil)
+	}
+	if c.tracer != nil {
+		c.tracer.record("expire", key, 0, nil)
+	}
+	return true
+}
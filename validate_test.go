@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Validate(t *testing.T) {
+	clk := &fakeClock{}
+	lc := NewLRUCache(1, 3, time.Second).Clock(clk)
+	lc.Put("1", "v1")
+	lc.Put("2", "v2")
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 1 failed")
+	}
+	var got interface{}
+	if !lc.Validate("1", 0, func(v interface{}) bool { got = v; return true }) || got != "v1" {
+		t.Error("case 2 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != "v1" {
+		t.Error("case 3 failed")
+	}
+	clk.Add(2 * time.Second)
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 4 failed")
+	}
+
+	// longer ttl
+	lc.Validate("1", time.Minute, func(interface{}) bool { return true })
+	clk.Add(30 * time.Second)
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 5 failed")
+	}
+
+	// invalid ones are deleted
+	if lc.Validate("2", 0, func(interface{}) bool { return false }) || lc.Len() != 1 {
+		t.Error("case 6 failed")
+	}
+	if lc.Validate("3", 0, func(interface{}) bool { return true }) {
+		t.Error("case 7 failed")
+	}
+
+	// replaced meanwhile
+	if lc.Validate("1", 0, func(interface{}) bool { lc.Put("1", "v3"); return false }) {
+		t.Error("case 8 failed")
+	}
+	if v, ok := lc.Get("1"); !ok || v != "v3" {
+		t.Error("case 9 failed")
+	}
+
+	// never expiring ones stay so
+	lc.PutWithTTL("4", "v4", 0)
+	lc.Validate("4", 0, func(interface{}) bool { return true })
+	clk.Add(time.Hour)
+	if _, ok := lc.Get("4"); !ok {
+		t.Error("case 10 failed")
+	}
+
+	lc.Put("5", "v5")
+	lc.Validate("5", time.Duration(math.MaxInt64), func(interface{}) bool { return true }) // saturated instead of overflowing
+	if _, ok := lc.Get("5"); !ok {
+		t.Error("case 11 failed")
+	}
+
+	var states []State
+	lc = NewLRUCache(1, 3, time.Second).Clock(clk).OnState(func(key string, from, to State) {
+		if from == Stale {
+			states = append(states, to)
+		}
+	})
+	lc.Put("1", "v1")
+	clk.Add(2 * time.Second)
+	lc.Validate("1", 0, func(interface{}) bool { return true })
+	if len(states) != 1 || states[0] != Ready {
+		t.Error("case 12 failed", states)
+	}
+}
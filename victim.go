@@ -0,0 +1,52 @@
+package cache
+
+// Candidate - an item that may be evicted, `Value` is as stored (i.e. encoded by `Pipeline` if any)
+type Candidate struct {
+	Key   string
+	Value interface{}
+}
+
+type victim struct {
+	n      int
+	choose func(cands []Candidate) int
+	cands  []Candidate // reused under bucket lock, it's per-bucket so no racing
+}
+
+// Victim - let the application choose which item to evict when a bucket is full,
+// `choose` gets at most `n` least recently used items (the least recent first) and returns the index of the victim,
+// out of range means the least recent one as usual, so domain-specific policies need no fork of the internal structures
+// `choose` is called with the lock of the bucket held, it must be fast and must not call back into the cache
+// `n` of `0` (or negative) or nil `choose` disables it, i.e. the least recent one is evicted as usual
+func (c *Cache) Victim(n int, choose func(cands []Candidate) int) *Cache {
+	if n <= 0 || choose == nil {
+		c.victims = nil
+		return c
+	}
+	c.victims = make([]*victim, len(c.insts))
+	for i := range c.victims {
+		c.victims[i] = &victim{n: n, choose: choose, cands: make([]Candidate, 0, n)}
+	}
+	return c
+}
+
+// evict the victim chosen by the hook before putting a new key into a full bucket, lock of the bucket must be held
+func (c *Cache) evict(key string, idx, level int) {
+	inst := c.insts[idx][level]
+	if _, ok := inst.hmap[key]; ok || inst.cap <= 0 || len(inst.hmap) < inst.cap {
+		return
+	}
+	v := c.victims[idx]
+	v.cands = v.cands[:0]
+	for e := inst.tail; e != nil && len(v.cands) < v.n; e = e.p {
+		v.cands = append(v.cands, Candidate{e.k, e.v.v})
+	}
+	// otherwise the tail is evicted by `put` itself
+	if i := v.choose(v.cands); i > 0 && i < len(v.cands) {
+		if w, ok := inst.del(v.cands[i].Key); ok {
+			c.evicted(v.cands[i].Key, idx, level, w)
+		}
+	}
+	for j := range v.cands {
+		v.cands[j] = Candidate{} // don't hold the values
+	}
+}
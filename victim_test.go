@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Victim(t *testing.T) {
+	var got []Candidate
+	lc := NewLRUCache(1, 3, time.Second).Victim(2, func(cands []Candidate) int {
+		got = append(got[:0], cands...)
+		for i, c := range cands {
+			if c.Value == "pinned" {
+				return 1 - i
+			}
+		}
+		return -1
+	})
+	lc.Put("1", "pinned")
+	lc.Put("2", "2")
+	lc.Put("3", "3")
+	if got != nil {
+		t.Error("case 1 failed")
+	}
+	lc.Put("3", "3") // replace, no eviction
+	if got != nil {
+		t.Error("case 2 failed")
+	}
+	lc.Put("4", "4")
+	if len(got) != 2 || got[0].Key != "1" || got[1].Key != "2" {
+		t.Error("case 3 failed", got)
+	}
+	if _, ok := lc.Get("1"); !ok {
+		t.Error("case 4 failed")
+	}
+	if _, ok := lc.Get("2"); ok {
+		t.Error("case 5 failed")
+	}
+	if s := lc.ShardStats(0); s.Evictions != 1 || s.Len != 3 {
+		t.Error("case 6 failed")
+	}
+	lc.Put("5", "5") // "3" is the tail now, default eviction
+	if _, ok := lc.Get("3"); ok {
+		t.Error("case 7 failed")
+	}
+	if s := lc.ShardStats(0); s.Evictions != 2 || s.Len != 3 {
+		t.Error("case 8 failed")
+	}
+
+	// plain lru without `choose`
+	if lc.Victim(2, nil).victims != nil || NewLRUCache(1, 3, 0).Victim(-1, func([]Candidate) int { return 0 }).victims != nil {
+		t.Error("case 9 failed")
+	}
+	for _, k := range []string{"6", "7", "8"} {
+		lc.Put(k, k)
+	}
+	if _, ok := lc.Get("1"); ok {
+		t.Error("case 10 failed")
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WarmError - keys that failed to load in `WarmParallel`, and their errors
+type WarmError struct {
+	Errs map[string]error
+}
+
+func (e *WarmError) Error() string {
+	for k, err := range e.Errs {
+		return fmt.Sprintf("cache: failed to warm %d keys, e.g. %q: %v", len(e.Errs), k, err)
+	}
+	return "cache: failed to warm 0 keys"
+}
+
+// WarmParallel - load `keys` with at most `parallelism` concurrent calls of `loader` and put them into cache,
+// for warming up at service start-up from a key manifest
+// keys that fail to load are skipped and reported together by a *WarmError after all others are done,
+// if `ctx` ends, no more loads are started and ctx.Err() is returned
+func (c *Cache) WarmParallel(ctx context.Context, keys []string,
+	loader func(ctx context.Context, key string) (interface{}, error), parallelism int) error {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs map[string]error
+		sem  = make(chan struct{}, parallelism)
+	)
+	for _, key := range keys {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(key string) {
+			v, err := loader(ctx, key)
+			if err == nil {
+				c.Put(key, v)
+			} else {
+				mu.Lock()
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[key] = err
+				mu.Unlock()
+			}
+			<-sem
+			wg.Done()
+		}(key)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return &WarmError{errs}
+	}
+	return nil
+}
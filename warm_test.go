@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_WarmParallel(t *testing.T) {
+	lc := NewLRUCache(4, 100, time.Second)
+	var cur, max int32
+	keys := make([]string, 50)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	err := lc.WarmParallel(context.Background(), keys, func(ctx context.Context, key string) (interface{}, error) {
+		if n := atomic.AddInt32(&cur, 1); n > atomic.LoadInt32(&max) {
+			atomic.StoreInt32(&max, n)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&cur, -1)
+		if key == "7" || key == "8" {
+			return nil, errors.New("boom")
+		}
+		return "v" + key, nil
+	}, 4)
+	if e, ok := err.(*WarmError); !ok || len(e.Errs) != 2 || e.Errs["7"] == nil || e.Error() == "" {
+		t.Error("case 1 failed: ", err)
+	}
+	if atomic.LoadInt32(&max) > 4 {
+		t.Error("case 2 failed: ", max)
+	}
+	if v, ok := lc.Get("42"); !ok || v != "v42" {
+		t.Error("case 3 failed")
+	}
+	if _, ok := lc.Get("7"); ok {
+		t.Error("case 4 failed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	err = lc.WarmParallel(ctx, keys, func(ctx context.Context, key string) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 3 {
+			cancel()
+		}
+		return key, nil
+	}, 1)
+	if err != context.Canceled || atomic.LoadInt32(&calls) >= 50 {
+		t.Error("case 5 failed: ", err, calls)
+	}
+
+	if err = lc.WarmParallel(context.Background(), keys, func(ctx context.Context, key string) (interface{}, error) {
+		return key, nil
+	}, 0); err != nil {
+		t.Error("case 6 failed: ", err)
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// countdown of an item
+type watch struct {
+	once sync.Once
+	ch   chan struct{}
+	t    *time.Timer
+}
+
+func (w *watch) close() {
+	w.once.Do(func() {
+		if w.t != nil {
+			w.t.Stop()
+		}
+		close(w.ch)
+	})
+}
+
+// remaining time before the item at specific level expires
+func (c *Cache) remaining(w *wrapper, level int) time.Duration {
+	if w.exp != 0 {
+		return time.Duration(w.exp-c.clock.Now()) + 1
+	}
+	return time.Duration(int64(c.expire[level])-(c.clock.Now()-w.ts)) + 1
+}
+
+// called when the timer of the item fires
+func (c *Cache) recheck(key string, idx int, w *wrapper) {
+	c.lock(idx)
+	if cur, level := c.peek(key, idx); cur == w {
+		// still alive, e.g. promoted to a level with longer expiration
+		if c.mortal(w, level) {
+			w.watch.t.Reset(c.remaining(w, level))
+		}
+	} else {
+		w.watch.close()
+	}
+	c.locks[idx].Unlock()
+}
+
+// start or restart the countdown of a watched item, e.g. after its expiration changed
+func (c *Cache) rearm(key string, idx, level int, w *wrapper) {
+	if !c.mortal(w, level) { // it only closes when the item leaves the cache
+		if w.watch.t != nil {
+			w.watch.t.Stop()
+		}
+	} else if w.watch.t != nil {
+		w.watch.t.Reset(c.remaining(w, level))
+	} else {
+		w.watch.t = time.AfterFunc(c.remaining(w, level), func() { c.recheck(key, idx, w) })
+	}
+}
+
+// ExpireAfter - get a channel that is closed when the item of `key` expires or leaves the cache
+// (deleted, evicted, or replaced by a new value), returns false if the key is absent or expired
+// so state machines (e.g. pending-operation trackers) can wait on cache lifetime instead of polling
+// the countdown runs on real timers, a replaced `Clock` only affects when the item is considered expired
+func (c *Cache) ExpireAfter(key string) (<-chan struct{}, bool) {
+	idx := hashCode(key) & c.mask
+	c.lock(idx)
+	w, level := c.peek(key, idx)
+	if w == nil {
+		c.locks[idx].Unlock()
+		return nil, false
+	}
+	if w.watch == nil {
+		atomic.StoreInt32(&c.watched, 1)
+		w.watch = &watch{ch: make(chan struct{})}
+		c.rearm(key, idx, level, w)
+	}
+	c.locks[idx].Unlock()
+	return w.watch.ch, true
+}
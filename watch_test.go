@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func closed(ch <-chan struct{}, wait time.Duration) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(wait):
+		return false
+	}
+}
+
+func Test_ExpireAfter(t *testing.T) {
+	lc := NewLRUCache(1, 2, 50*time.Millisecond)
+	if _, ok := lc.ExpireAfter("1"); ok {
+		t.Error("case 1 failed")
+	}
+	lc.Put("1", "1")
+	ch, ok := lc.ExpireAfter("1")
+	if !ok {
+		t.Error("case 2 failed")
+	}
+	if ch2, _ := lc.ExpireAfter("1"); ch2 != ch {
+		t.Error("case 3 failed")
+	}
+	if closed(ch, 20*time.Millisecond) {
+		t.Error("case 4 failed")
+	}
+	if !closed(ch, 100*time.Millisecond) {
+		t.Error("case 5 failed")
+	}
+
+	// deleted
+	lc.Put("1", "1")
+	ch, _ = lc.ExpireAfter("1")
+	lc.Del("1")
+	if !closed(ch, 10*time.Millisecond) {
+		t.Error("case 6 failed")
+	}
+
+	// replaced
+	lc.Put("1", "1")
+	ch, _ = lc.ExpireAfter("1")
+	lc.Put("1", "2")
+	if !closed(ch, 10*time.Millisecond) {
+		t.Error("case 7 failed")
+	}
+
+	// evicted
+	ch, _ = lc.ExpireAfter("1")
+	lc.Put("2", "2")
+	lc.Put("3", "3")
+	if !closed(ch, 10*time.Millisecond) {
+		t.Error("case 8 failed")
+	}
+
+	// replaced all
+	ch, _ = lc.ExpireAfter("3")
+	lc.ReplaceAll(map[string]interface{}{"3": "3"})
+	if !closed(ch, 10*time.Millisecond) {
+		t.Error("case 9 failed")
+	}
+}
+
+func Test_ExpireAfterLFU(t *testing.T) {
+	lc := NewLRUCache(1, 2, 30*time.Millisecond).LFU(2).LFUExpire(time.Hour)
+	lc.Put("1", "1")
+	ch, _ := lc.ExpireAfter("1")
+	lc.Get("1") // l0 -> l1, lives longer
+	if closed(ch, 60*time.Millisecond) {
+		t.Error("case 1 failed")
+	}
+	lc.Del("1")
+	if !closed(ch, 10*time.Millisecond) {
+		t.Error("case 2 failed")
+	}
+
+	lc = NewLRUCache(1, 2, 10*time.Millisecond).Sweep(2)
+	lc.Put("1", "1")
+	ch, _ = lc.ExpireAfter("1")
+	time.Sleep(20 * time.Millisecond)
+	lc.Put("2", "2") // sweeps "1"
+	if !closed(ch, 10*time.Millisecond) {
+		t.Error("case 3 failed")
+	}
+}